@@ -0,0 +1,151 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"sync"
+)
+
+// AveragedKaczmarz returns the solution of a consistent system A*x=b using the averaged (Cimmino-style)
+// randomized Kaczmarz algorithm of Moorman, Tu, Molitor and Needell.
+//
+// Every iteration samples samplesPerStep rows, computes the projection of x onto each of their hyperplanes
+// in its own goroutine and moves x by the weighted average of the projection steps. Averaging independent
+// projections parallelizes well and damps the effect of noisy rows.
+//
+// Parameters:
+// A is a mat.Matrix representing the system. mat.Dense rows are read without copying.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// samplesPerStep is the number of rows sampled and averaged at every iteration.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithAveragingWeights.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the averaging
+// weights, the row probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// A samplesPerStep smaller than 1 is treated as 1, which with the default weights is plain RandomizedKaczmarz.
+// The rows are sampled with replacement, so a row can be averaged more than once in the same iteration.
+// The average is a shorter step than any single projection. WithRelaxation values above 1 can make up for it,
+// but unlike plain Kaczmarz the largest stable value depends on the system and on samplesPerStep.
+func AveragedKaczmarz(A mat.Matrix, b *mat.VecDense, samplesPerStep, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+	if samplesPerStep < 1 {
+		samplesPerStep = 1
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, (rowsA+samplesPerStep-1)/samplesPerStep)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	weights := o.rowWeights
+	if weights == nil {
+		weights = make([]float64, rowsA)
+		for row := range weights {
+			weights[row] = 1
+		}
+	}
+	if err := checkWeights(weights, rowsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// Every sample has its own row reader, since the rows are all read before x moves, and its own projection step
+	readers := make([]*rowReader, samplesPerStep)
+	chosen := make([]*mat.VecDense, samplesPerStep)
+	steps := make([]float64, samplesPerStep)
+	for k := range readers {
+		readers[k] = newRowReader(A)
+	}
+
+	track, err := newTracker(o, tolerance, keepErrors, x, residualOf(A, x, b))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each row of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+
+	frobeniusA := rowsProbability(probsA, normsA, A)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// Every sample has a goroutine of its own, started once rather than at every iteration, which computes the
+	// projection step of the rows it is sent. x only moves once all of them are done.
+	samples := make([]chan int, samplesPerStep)
+	waitGroup := sync.WaitGroup{}
+	for k := range samples {
+		samples[k] = make(chan int)
+		go func(k int) {
+			for row := range samples[k] {
+				chosen[k] = readers[k].at(row)
+				steps[k] = weights[row] * ((b.AtVec(row) - mat.Dot(chosen[k], x)) / normsA[row]) / normsA[row]
+				waitGroup.Done()
+			}
+		}(k)
+	}
+	defer func() {
+		for _, sample := range samples {
+			close(sample)
+		}
+	}()
+
+	// STEP 2.
+	// Computing the projections onto the sampled rows in parallel and moving x by their weighted average
+	for i := 0; i < iterations; i++ {
+		waitGroup.Add(samplesPerStep)
+		for _, sample := range samples {
+			sample <- samplerA.Next()
+		}
+		waitGroup.Wait()
+
+		// The steps are added in the order the rows were sampled so that seeded runs stay reproducible
+		for k := range chosen {
+			x.AddScaledVec(x, o.relaxation*steps[k]/float64(samplesPerStep), chosen[k])
+		}
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
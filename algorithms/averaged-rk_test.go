@@ -0,0 +1,84 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"testing"
+)
+
+func TestAveragedKaczmarz(t *testing.T) {
+	A, b, want := smallSystem()
+
+	x, _, err := AveragedKaczmarz(A, b, 3, 2000, 0, false, WithSeed(1), WithRelaxation(2))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "x", x.RawVector().Data, want)
+
+	// Rows that can't be viewed in place are read into a buffer of their own for every sample
+	again, _, err := AveragedKaczmarz(mat.DenseCopyOf(A.T()).T(), b, 3, 2000, 0, false, WithSeed(1), WithRelaxation(2))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !mat.EqualApprox(&x, &again, 1e-12) {
+		t.Errorf("x = %v through a transpose, want %v", again.RawVector().Data, x.RawVector().Data)
+	}
+}
+
+// TestAveragedKaczmarzParallelSteps is meant to be run with -race as well, the steps of every iteration are
+// computed on goroutines and must add up to the serial average of the same projections
+func TestAveragedKaczmarzParallelSteps(t *testing.T) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(2)), 40, 6)
+	b := mat.NewVecDense(40, mat.Col(nil, 0, B))
+	b.SetVec(0, b.AtVec(0)+1)
+
+	const samplesPerStep, iterations = 4, 300
+	x, _, err := AveragedKaczmarz(A, b, samplesPerStep, iterations, 0, false, WithSeed(3), WithRelaxation(2))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The same samples, drawn in the same order, with every step computed before x moves
+	next := seededRows(A, 3)
+	want := mat.NewVecDense(6, nil)
+	rows := make([]int, samplesPerStep)
+	steps := make([]float64, samplesPerStep)
+	for i := 0; i < iterations; i++ {
+		for k := range rows {
+			rows[k] = next()
+			a := A.RowView(rows[k])
+			steps[k] = (b.AtVec(rows[k]) - mat.Dot(a, want)) / mat.Dot(a, a)
+		}
+		for k, row := range rows {
+			want.AddScaledVec(want, 2*steps[k]/samplesPerStep, A.RowView(row))
+		}
+	}
+	checkAgainstReference(t, "AveragedKaczmarz", &x, want.RawVector().Data, 1e-10)
+}
+
+// TestAveragedKaczmarzAllocations checks that the iterations allocate nothing, only the setup does
+func TestAveragedKaczmarzAllocations(t *testing.T) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(1)), 60, 10)
+	b := mat.NewVecDense(60, mat.Col(nil, 0, B))
+
+	few := testing.AllocsPerRun(5, func() {
+		AveragedKaczmarz(A, b, 4, 10, 0, false, WithSeed(1))
+	})
+	many := testing.AllocsPerRun(5, func() {
+		AveragedKaczmarz(A, b, 4, 1000, 0, false, WithSeed(1))
+	})
+	// A handful more is left to the runtime, like the race detector, but not one per iteration
+	if many > few+10 {
+		t.Errorf("%g allocations for 10 iterations but %g for 1000", few, many)
+	}
+}
+
+func BenchmarkAveragedKaczmarz(bench *testing.B) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(1)), 200, 20)
+	b := mat.NewVecDense(200, mat.Col(nil, 0, B))
+
+	bench.ReportAllocs()
+	for n := 0; n < bench.N; n++ {
+		AveragedKaczmarz(A, b, 8, 2000, 0, false, WithSeed(1))
+	}
+}
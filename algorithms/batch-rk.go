@@ -0,0 +1,86 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"runtime"
+	"sync"
+)
+
+// System is one of the independent systems A*X=B solved by SolveBatch
+type System struct {
+	// A is the matrix of the system, B holds its right-hand sides, one per column
+	A mat.Matrix
+	B *mat.Dense
+	// Iterations and Tolerance are the iterations and tolerance of RandomizedKaczmarzMulti
+	Iterations int
+	Tolerance  float64
+}
+
+// SolveBatch solves many independent systems concurrently with RandomizedKaczmarzMulti.
+//
+// Small systems don't have enough work to share between cores within a solve, but a batch of them does: the
+// systems are handed out to a pool of GOMAXPROCS workers, each running one solve at a time.
+//
+// Parameters:
+// systems are the systems to solve. Their matrices are only read and may be shared between systems.
+// opts are optional settings such as WithSeed or WithRelaxation, applied to every solve.
+//
+// Returns the solutions and the errors of the systems, in the order of systems. The solution of a system whose
+// solve failed before iterating is nil.
+//
+// Notes:
+// System k draws its rows from substream k of a master seed, see WithSubstream, so no two solves share a random
+// source. The master seed is the one given to WithSeed, which makes the whole batch reproducible whatever the
+// number of workers, or a random one.
+// The errors aren't returned. They are only computed for the systems with a positive tolerance, after every
+// iteration as in RandomizedKaczmarzMulti, so that those solves stop once they reach it.
+// Options that store results, such as WithHistory, WithStopReason or WithResidual, and samplers given to
+// WithRowSampler would be shared by concurrent solves and must not be used.
+func SolveBatch(systems []System, opts ...Option) ([]*mat.Dense, []error) {
+
+	// STEP 0.
+	// Initialization of variables
+	master := rand.Uint64()
+	if o := newOptions(opts); o.seeded {
+		master = o.seed
+	}
+
+	solutions := make([]*mat.Dense, len(systems))
+	errs := make([]error, len(systems))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(systems) {
+		workers = len(systems)
+	}
+
+	// STEP 1.
+	// Handing out the systems to the workers, which store each solution at the index of its system
+	jobs := make(chan int)
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer waitGroup.Done()
+			for k := range jobs {
+				system := systems[k]
+				runOpts := append(append([]Option(nil), opts...), WithSubstream(master, k))
+
+				// The errors are only computed to check a positive tolerance
+				X, _, err := RandomizedKaczmarzMulti(system.A, system.B, system.Iterations, system.Tolerance, system.Tolerance > 0, runOpts...)
+				if !X.IsEmpty() {
+					solutions[k] = &X
+				}
+				errs[k] = err
+			}
+		}()
+	}
+
+	for k := range systems {
+		jobs <- k
+	}
+	close(jobs)
+	waitGroup.Wait()
+
+	return solutions, errs
+}
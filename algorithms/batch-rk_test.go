@@ -0,0 +1,67 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+// randomSystem returns a consistent system with a random rows*cols matrix, cols right-hand sides and its solution
+func randomSystem(rnd *rand.Rand, rows, cols int) (*mat.Dense, *mat.Dense, *mat.Dense) {
+	A := mat.NewDense(rows, cols, nil)
+	X := mat.NewDense(cols, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			A.Set(i, j, rnd.NormFloat64())
+		}
+	}
+	for i := 0; i < cols; i++ {
+		for j := 0; j < cols; j++ {
+			X.Set(i, j, rnd.NormFloat64())
+		}
+	}
+	B := mat.NewDense(rows, cols, nil)
+	B.Mul(A, X)
+
+	return A, B, X
+}
+
+// TestSolveBatch is meant to be run with -race as well, the matrices and the options are shared by the workers
+func TestSolveBatch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	shared, _, _ := randomSystem(rnd, 20, 4)
+
+	// Half of the systems share the same matrix, and every solve can only finish by reaching its tolerance
+	systems := make([]System, 100)
+	truths := make([]*mat.Dense, len(systems))
+	for k := range systems {
+		A, _, X := randomSystem(rnd, 20, 4)
+		if k%2 == 0 {
+			A = shared
+		}
+		B := mat.NewDense(20, 4, nil)
+		B.Mul(A, X)
+
+		systems[k] = System{A: A, B: B, Iterations: math.MaxInt32, Tolerance: 1e-20}
+		truths[k] = X
+	}
+
+	solutions, errs := SolveBatch(systems, WithSeed(1))
+	for k := range systems {
+		if errs[k] != nil {
+			t.Fatalf("system %d: unexpected error %v", k, errs[k])
+		}
+		if !mat.EqualApprox(solutions[k], truths[k], 1e-8) {
+			t.Fatalf("system %d: X = %v, want %v", k, mat.Formatted(solutions[k]), mat.Formatted(truths[k]))
+		}
+	}
+
+	// The same seed gives the same solutions whatever the order the workers ran in
+	again, _ := SolveBatch(systems, WithSeed(1))
+	for k := range systems {
+		if !mat.Equal(solutions[k], again[k]) {
+			t.Fatalf("system %d: the solutions of two seeded batches differ", k)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package algorithms
+
+import (
+	"github.com/alexandru-balan/go-rk-rk/utils"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BlockRandomizedKaczmarz returns the solution of a consistent system A*x=b using the block randomized
+// Kaczmarz algorithm.
+//
+// Instead of a single row, every iteration chooses a block of rows with probability proportional to its squared
+// frobenius norm and projects x onto the intersection of their hyperplanes. The projection is the minimum norm
+// least-squares solution of the block subsystem, computed from the pseudo-inverse of the block.
+//
+// Parameters:
+// A is a mat.Matrix representing the system.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// blockSize is the number of consecutive rows in every block. It is ignored if WithPartition is used.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithPartition.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the partition
+// has an empty block or an out of range row and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// A blockSize smaller than 1 is treated as 1 and the last block holds the remaining rows.
+// The pseudo-inverses of all blocks are computed once, which needs as much memory as A.
+func BlockRandomizedKaczmarz(A mat.Matrix, b *mat.VecDense, blockSize, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+	if blockSize < 1 {
+		blockSize = 1
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// Reader of the rows of A, which reuses the same vector for every row
+	readerA := newRowReader(A)
+
+	track, err := newTracker(o, tolerance, keepErrors, x, residualOf(A, x, b))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	blocks := o.partition
+	if blocks == nil {
+		blocks = contiguousBlocks(rowsA, blockSize)
+	}
+	if err := checkPartition(blocks, rowsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	iterations = o.epochIterations(iterations, len(blocks))
+
+	// STEP 1.
+	// Computing the pseudo-inverse and the frobenius norm of every block
+	pinvs := make([]*mat.Dense, len(blocks))
+	probsBlocks := make([]float64, len(blocks))
+	normsBlocks := make([]float64, len(blocks))
+
+	// Every block also gets the vector of its residuals, so that the iterations allocate nothing
+	residuals := make([]*mat.VecDense, len(blocks))
+	step := mat.NewVecDense(colsA, nil)
+
+	for k, block := range blocks {
+		Ablock := mat.NewDense(len(block), colsA, nil)
+		for r, row := range block {
+			mat.Row(Ablock.RawRowView(r), row, A)
+		}
+
+		pinvs[k] = pseudoInverse(Ablock)
+		normsBlocks[k] = FrobeniusNorm(Ablock)
+		residuals[k] = mat.NewVecDense(len(block), nil)
+	}
+
+	// STEP 2.
+	// Computing the probability of each block from the norms, without squaring them
+	frobeniusA := fillProbabilities(probsBlocks, normsBlocks, func(k int) float64 {
+		return normsBlocks[k]
+	})
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerBlocks, err := o.newSampler("block", "A", probsBlocks, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 3.
+	// Projecting x onto the intersection of the hyperplanes of a randomly chosen block
+	for i := 0; i < iterations; i++ {
+		randBlock := samplerBlocks.Next()
+		block := blocks[randBlock]
+
+		residual := residuals[randBlock]
+		for r, row := range block {
+			residual.SetVec(r, b.AtVec(row)-mat.Dot(readerA.at(row), x))
+		}
+
+		step.MulVec(pinvs[randBlock], residual)
+		x.AddScaledVec(x, o.relaxation, step)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
+
+// contiguousBlocks partitions the row indices [0, rows) into consecutive blocks of blockSize rows
+func contiguousBlocks(rows, blockSize int) [][]int {
+	var blocks [][]int
+	for start := 0; start < rows; start += blockSize {
+		end := start + blockSize
+		if end > rows {
+			end = rows
+		}
+
+		block := make([]int, end-start)
+		for r := range block {
+			block[r] = start + r
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+// pseudoInverse returns the Moore-Penrose pseudo-inverse of a matrix computed from its thin SVD.
+//
+// Singular values that are negligible compared to the largest one are treated as zero, with the same cutoff as
+// utils.LeastSquares, so rank-deficient matrices are handled.
+func pseudoInverse(matrix *mat.Dense) *mat.Dense {
+	rows, cols := matrix.Dims()
+
+	svd := new(mat.SVD)
+	if !svd.Factorize(matrix, mat.SVDThin) {
+		return mat.NewDense(cols, rows, nil)
+	}
+
+	Left := new(mat.Dense)
+	Right := new(mat.Dense)
+	svd.UTo(Left)
+	svd.VTo(Right)
+	values := svd.Values(nil)
+
+	inverted := make([]float64, len(values))
+	rank := utils.NumericalRank(values, rows, cols)
+	for i := 0; i < rank; i++ {
+		inverted[i] = 1 / values[i]
+	}
+
+	pinv := new(mat.Dense)
+	pinv.Product(Right, mat.NewDiagDense(len(inverted), inverted), Left.T())
+
+	return pinv
+}
@@ -0,0 +1,63 @@
+package algorithms
+
+import (
+	"errors"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"testing"
+)
+
+func TestBlockRandomizedKaczmarz(t *testing.T) {
+	A, b, want := smallSystem()
+
+	x, errs, err := BlockRandomizedKaczmarz(A, b, 2, 1000, 1e-20, true, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "contiguous blocks", x.RawVector().Data, want)
+	if len(errs) == 0 || len(errs) >= 1000 {
+		t.Errorf("%d iterations, want the solve to stop at the tolerance", len(errs))
+	}
+
+	x, _, err = BlockRandomizedKaczmarz(A, b, 0, 1000, 0, false, WithSeed(1), WithPartition([][]int{{0, 3}, {1, 2}}))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "partition", x.RawVector().Data, want)
+
+	// A single block holding every row is solved in one projection
+	x, _, _ = BlockRandomizedKaczmarz(A, b, 4, 1, 0, false)
+	checkSolution(t, "single block", x.RawVector().Data, want)
+
+	_, _, err = BlockRandomizedKaczmarz(A, b, 2, 10, 0, false, WithPartition([][]int{{0, 1}, {2, 4}}))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("got error %v, want %v for a row out of range", err, ErrInvalidOption)
+	}
+}
+
+// TestBlockRandomizedKaczmarzAllocations checks that the iterations allocate nothing, only the setup does
+func TestBlockRandomizedKaczmarzAllocations(t *testing.T) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(1)), 60, 10)
+	b := mat.NewVecDense(60, mat.Col(nil, 0, B))
+
+	few := testing.AllocsPerRun(5, func() {
+		BlockRandomizedKaczmarz(A, b, 7, 10, 0, false, WithSeed(1))
+	})
+	many := testing.AllocsPerRun(5, func() {
+		BlockRandomizedKaczmarz(A, b, 7, 1000, 0, false, WithSeed(1))
+	})
+	// A handful more is left to the runtime, like the race detector, but not one per iteration
+	if many > few+10 {
+		t.Errorf("%g allocations for 10 iterations but %g for 1000", few, many)
+	}
+}
+
+func BenchmarkBlockRandomizedKaczmarz(bench *testing.B) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(1)), 200, 20)
+	b := mat.NewVecDense(200, mat.Col(nil, 0, B))
+
+	bench.ReportAllocs()
+	for n := 0; n < bench.N; n++ {
+		BlockRandomizedKaczmarz(A, b, 10, 2000, 0, false, WithSeed(1))
+	}
+}
@@ -0,0 +1,132 @@
+package algorithms
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/cmplx"
+)
+
+// RandomizedKaczmarzCmplx returns the solution of a consistent complex system A*x=b using the randomized
+// Kaczmarz algorithm.
+//
+// It is RandomizedKaczmarz for complex matrices: the rows are chosen with probability proportional to their
+// squared modulus norm and x is projected along the conjugate of the chosen row, i.e. a column of the
+// conjugate transpose of A.
+//
+// Parameters:
+// A is a mat.CDense matrix representing the system.
+// b is a []complex128 vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz; the errors are the squared moduli
+// of the residuals.
+// gonum has no complex vector type, so the vectors are plain slices. WithInitialGuess, WithMomentum and
+// ErrorMetricSolutionGap only apply to real systems.
+func RandomizedKaczmarzCmplx(A *mat.CDense, b []complex128, iterations int, tolerance float64, keepErrors bool, opts ...Option) ([]complex128, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if len(b) != rowsA {
+		return nil, nil, fmt.Errorf("%w: b has %d rows, expected %d to match A", ErrDimensionMismatch, len(b), rowsA)
+	}
+
+	raw := A.RawCMatrix()
+	if err := o.checkFiniteCmplx(raw.Data, rowsA, colsA, raw.Stride, "A"); err != nil {
+		return nil, nil, err
+	}
+	if err := o.checkFiniteCmplx(b, rowsA, 1, 1, "b"); err != nil {
+		return nil, nil, err
+	}
+	row := func(i int) []complex128 {
+		return raw.Data[i*raw.Stride : i*raw.Stride+colsA]
+	}
+
+	x := make([]complex128, colsA)
+
+	track, err := newTracker(o, tolerance, keepErrors, nil, func() float64 {
+		residual := 0.0
+		for i := 0; i < rowsA; i++ {
+			diff := b[i] - cmplxDot(row(i), x)
+			residual += real(diff)*real(diff) + imag(diff)*imag(diff)
+		}
+		return residual
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// STEP 1.
+	// Computing the norm of each row of A from the moduli of its entries, combined with math.Hypot so that
+	// they don't overflow, and the frobenius norm from them
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+	frobeniusA := fillProbabilities(probsA, normsA, func(i int) float64 {
+		norm := 0.0
+		for _, value := range row(i) {
+			norm = math.Hypot(norm, cmplx.Abs(value))
+		}
+		return norm
+	})
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return nil, nil, err
+	}
+
+	// STEP 2.
+	// Applying the row probabilities given as options
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return nil, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 3.
+	// Projecting x onto the hyperplane of a randomly chosen row, along the conjugate of the row
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+		chosenA := row(randA)
+
+		residual := (b[randA] - cmplxDot(chosenA, x)) / complex(normsA[randA], 0)
+		step := complex(o.relaxation/normsA[randA], 0) * residual
+		for j, value := range chosenA {
+			x[j] += step * complex(real(value), -imag(value))
+		}
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	return x, track.errors, track.err
+}
+
+// cmplxDot returns the product sum(row[j]*x[j]) of a row of a complex matrix with a vector, without conjugation
+func cmplxDot(row, x []complex128) complex128 {
+	var sum complex128
+	for j, value := range row {
+		sum += value * x[j]
+	}
+
+	return sum
+}
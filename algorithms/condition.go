@@ -0,0 +1,107 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// EstimateConditionNumber estimates the condition number sigma_max/sigma_min of A, which bounds how fast the
+// Kaczmarz solvers can converge: the larger it is, the slower they are.
+//
+// The largest eigenvalue of A^T*A is estimated with iters steps of power iteration and the smallest with iters
+// steps of inverse power iteration through a Cholesky factorization. When A has more columns than rows A*A^T
+// is used instead, which has the same non-zero eigenvalues and is smaller. Their ratio is the squared
+// condition number.
+//
+// Parameters:
+// A is a mat.Matrix representing the system.
+// iters is the number of power iteration steps for each end of the spectrum. Pass a negative number to use 100.
+//
+// Returns the estimated condition number. +Inf is returned if A doesn't have full rank and NaN if A is zero.
+//
+// Notes:
+// The estimate only needs the smaller Gram matrix of A, but it costs as much memory. A few tens of steps are
+// usually enough, more are needed when the extreme singular values are close to the next ones.
+func EstimateConditionNumber(A mat.Matrix, iters int) float64 {
+	if iters < 0 {
+		iters = 100
+	}
+
+	// The Gram matrix is the smaller of A^T*A and A*A^T
+	rows, cols := A.Dims()
+	gram := new(mat.SymDense)
+	if rows >= cols {
+		gram.SymOuterK(1, A.T())
+	} else {
+		gram.SymOuterK(1, A)
+	}
+	n := gram.Symmetric()
+
+	// STEP 1.
+	// Estimating the largest eigenvalue with power iteration
+	v := mat.NewVecDense(n, nil)
+	w := mat.NewVecDense(n, nil)
+	start := func() {
+		for i := 0; i < n; i++ {
+			v.SetVec(i, 1/math.Sqrt(float64(n)))
+		}
+	}
+
+	start()
+	largest := 0.0
+	for k := 0; k < iters; k++ {
+		w.MulVec(gram, v)
+		largest = mat.Dot(v, w)
+
+		norm := EuclideanNorm(w)
+		if norm == 0 {
+			break
+		}
+		v.ScaleVec(1/norm, w)
+	}
+	if largest == 0 {
+		return math.NaN()
+	}
+
+	// STEP 2.
+	// Estimating the smallest eigenvalue with inverse power iteration, which fails if the Gram matrix is singular
+	var chol mat.Cholesky
+	if !chol.Factorize(gram) {
+		return math.Inf(1)
+	}
+
+	start()
+	inverse := 0.0
+	for k := 0; k < iters; k++ {
+		if err := chol.SolveVecTo(w, v); err != nil {
+			return math.Inf(1)
+		}
+		inverse = mat.Dot(v, w)
+
+		v.ScaleVec(1/EuclideanNorm(w), w)
+	}
+	if inverse <= 0 {
+		return math.Inf(1)
+	}
+
+	return math.Sqrt(largest * inverse)
+}
+
+// estimateRank reports whether A looks rank deficient, see WithRankCheck, together with its estimated
+// condition number
+func estimateRank(A mat.Matrix) (bool, float64) {
+	rows, cols := A.Dims()
+	cond := EstimateConditionNumber(A, 30)
+
+	return cols > rows || !(cond < 1e8), cond
+}
+
+// reportRank stores whether the matrix called name looks rank deficient for WithRankCheck and logs a warning
+// if it does
+func (o *options) reportRank(deficient bool, cond float64, name string) {
+	*o.rankDeficient = deficient
+	if deficient && o.logger != nil {
+		o.logger.Printf("warning: %s looks rank deficient (estimated condition number %g), the solution isn't unique "+
+			"and the one closest to the starting point is returned", name, cond)
+	}
+}
@@ -0,0 +1,137 @@
+package algorithms
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// RandomizedKaczmarzF32 returns the solution of a consistent system A*x=b whose matrix is stored in float32
+// using the randomized Kaczmarz algorithm.
+//
+// It is RandomizedKaczmarz for data produced in single precision: A is read in place, so a large matrix doesn't
+// need a float64 copy twice its size. Only x, the row norms and the dot products are kept in float64.
+//
+// Parameters:
+// A is a blas32.General matrix representing the system, whose rows are read without copying.
+// b is a []float32 vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// Every product of a row with x is accumulated in float64, so the iteration itself loses nothing, but A and b
+// only hold about 7 significant digits: x solves the rounded system, which is at most about cond(A)*6e-8 away
+// from the solution of the exact one in relative terms. On well-conditioned systems this is the same solution as
+// RandomizedKaczmarz up to the rounding of the data; tolerances below the squared rounding of b can't be reached
+// on inconsistent rounded systems.
+// WithInitialGuess applies as in RandomizedKaczmarz; WithMomentum, WithTikhonov and the other options of the
+// Solver only apply to float64 systems.
+func RandomizedKaczmarzF32(A blas32.General, b []float32, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Rows, A.Cols
+	iterations = o.epochIterations(iterations, rowsA)
+	if len(b) != rowsA {
+		return mat.VecDense{}, nil, fmt.Errorf("%w: b has %d rows, expected %d to match A", ErrDimensionMismatch, len(b), rowsA)
+	}
+
+	if err := o.checkFinite32(A.Data, rowsA, colsA, A.Stride, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite32(b, rowsA, 1, 1, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	row := func(i int) []float32 {
+		return A.Data[i*A.Stride : i*A.Stride+colsA]
+	}
+
+	start, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	x := start.RawVector().Data
+
+	track, err := newTracker(o, tolerance, keepErrors, start, func() float64 {
+		residual := 0.0
+		for i := 0; i < rowsA; i++ {
+			diff := float64(b[i]) - dot32(row(i), x)
+			residual += diff * diff
+		}
+		return residual
+	})
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the norm of each row of A in float64, where the squares of float32 values can't overflow,
+	// and the frobenius norm from them
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+	frobeniusA := fillProbabilities(probsA, normsA, func(i int) float64 {
+		squared := 0.0
+		for _, value := range row(i) {
+			squared += float64(value) * float64(value)
+		}
+		return math.Sqrt(squared)
+	})
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 2.
+	// Applying the row probabilities given as options
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 3.
+	// Projecting x onto the hyperplane of a randomly chosen row
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+		chosenA := row(randA)
+
+		step := o.relaxation * ((float64(b[randA]) - dot32(chosenA, x)) / normsA[randA]) / normsA[randA]
+		for j, value := range chosenA {
+			x[j] += step * float64(value)
+		}
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	return *start, track.errors, track.err
+}
+
+// dot32 returns the product of a float32 row with x, accumulated in float64
+func dot32(row []float32, x []float64) float64 {
+	sum := 0.0
+	for j, value := range row {
+		sum += float64(value) * x[j]
+	}
+
+	return sum
+}
@@ -0,0 +1,149 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// GreedyRandomizedKaczmarz returns the solution of a consistent system A*x=b using the greedy randomized
+// Kaczmarz algorithm of Bai and Wu.
+//
+// At every iteration the residual r=b-A*x is computed and only the rows whose relative residual
+// |r_i|^2/||a_i||^2 is large enough are eligible. The threshold is
+// theta*max_i(|r_i|^2/||a_i||^2)/||r||^2 + (1-theta)/||A||_F^2, scaled by ||r||^2*||a_i||^2, and an eligible row is
+// chosen with probability proportional to its squared residual.
+//
+// Parameters:
+// A is a mat.Matrix representing the system. mat.Dense rows are read without copying.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// theta is the relaxation constant of the threshold, between 0 and 1. 0.5 is the original algorithm,
+// larger values are greedier.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed or WithRelaxation.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// Every iteration computes the full residual, so a single iteration is as expensive as a pass over A.
+// The algorithm stops early if the residual becomes exactly zero or is left only on rows that are entirely zero.
+func GreedyRandomizedKaczmarz(A mat.Matrix, b *mat.VecDense, theta float64, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	residual := mat.NewVecDense(rowsA, nil)
+	weights := make([]float64, rowsA)
+
+	// Reader of the rows of A, which reuses the same vector for every row
+	readerA := newRowReader(A)
+
+	track, err := newTracker(o, tolerance, keepErrors, x, residualOf(A, x, b))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the norm of each row of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+
+	frobeniusA := rowsProbability(probsA, normsA, A)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 2.
+	// Projecting x onto the hyperplane of a row chosen among those with a large residual
+	for i := 0; i < iterations; i++ {
+		residual.MulVec(A, x)
+		residual.SubVec(b, residual)
+
+		residualNorm := EuclideanNorm(residual)
+		if residualNorm == 0 {
+			track.stop(StopTolerance)
+			break
+		}
+
+		// The residuals are divided by the norms instead of squared, |r_i|/(||r||*||a_i||) is the square root
+		// of the relative residual and the squares of large residuals or norms would overflow
+		largest, greediest := 0.0, 0
+		for row := 0; row < rowsA; row++ {
+			if normsA[row] == 0 {
+				continue
+			}
+			relative := math.Abs(residual.AtVec(row)/residualNorm) / normsA[row]
+			if relative > largest {
+				largest, greediest = relative, row
+			}
+		}
+
+		// Only zero rows have a residual, no projection can improve x
+		if largest == 0 {
+			track.stop(StopStagnation)
+			break
+		}
+
+		// The weights are normalized since GetRandomRow can't sample from weights that sum to almost zero
+		// The threshold is the square root of theta*largest^2 + (1-theta)/||A||_F^2, compared with the square roots
+		// of the relative residuals
+		threshold := math.Hypot(math.Sqrt(theta)*largest, math.Sqrt(1-theta)/frobeniusA)
+		eligible := 0.0
+		for row := 0; row < rowsA; row++ {
+			ratio := residual.AtVec(row) / residualNorm
+			// The greediest row always passes the threshold, rounding must not leave the set empty.
+			// Zero rows are never eligible, even with a non-zero residual, since there's nothing to project on.
+			if normsA[row] > 0 && (math.Abs(ratio)/normsA[row] >= threshold || row == greediest) {
+				weights[row] = ratio * ratio
+				eligible += weights[row]
+			} else {
+				weights[row] = 0
+			}
+		}
+		for row := range weights {
+			weights[row] /= eligible
+		}
+
+		randA := GetRandomRow(weights, src)
+
+		chosenA := readerA.at(randA)
+
+		x.AddScaledVec(
+			x,
+			o.relaxation*(residual.AtVec(randA)/normsA[randA])/normsA[randA],
+			chosenA)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
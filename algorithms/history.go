@@ -0,0 +1,271 @@
+package algorithms
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"time"
+)
+
+// ConvergenceHistory holds the raw convergence data of a solve, see WithHistory.
+type ConvergenceHistory struct {
+	// Iterations holds the iteration after which each residual was recorded
+	Iterations []int
+	// Residuals holds the errors returned by the solvers, the squared residuals unless WithErrorMetric says otherwise
+	Residuals []float64
+	// Elapsed holds the time since the start of the solve at which each residual was recorded.
+	// It is only filled if WithTiming is used.
+	Elapsed []time.Duration
+}
+
+// tracker computes the error of a solve after every iteration and records it
+type tracker struct {
+	o          *options
+	tolerance  float64
+	keepErrors bool
+	errors     []float64
+	start      time.Time
+	residual   func() float64
+	x          *mat.VecDense
+	// recent holds the last errors, as many as needed to check for stagnation
+	recent []float64
+	// best is a copy of x with the lowest error so far and bestErr its error, see WithReturnBestIterate
+	best    *mat.VecDense
+	bestErr float64
+	// reason tells why the solve stopped, see WithStopReason
+	reason StopReason
+	// err is set when the solve has to stop with an error, see WithFiniteCheck
+	err error
+}
+
+// newTracker starts tracking a solve of the solution x whose squared residual is computed by residual.
+//
+// With ErrorMetricSolutionGap the error is the squared distance from x to the true solution instead of the residual.
+// x is nil for solvers that don't support that metric. An ErrInvalidOption is returned if the metric can't be
+// used with x or the true solution given to WithTrueSolution.
+func newTracker(o *options, tolerance float64, keepErrors bool, x *mat.VecDense, residual func() float64) (*tracker, error) {
+	if o.metric == ErrorMetricSolutionGap {
+		switch {
+		case x == nil:
+			return nil, fmt.Errorf("%w: this solver doesn't support the solution gap error metric", ErrInvalidOption)
+		case o.solution == nil:
+			return nil, fmt.Errorf("%w: the solution gap error metric needs WithTrueSolution", ErrInvalidOption)
+		case o.solution.Len() != x.Len():
+			return nil, fmt.Errorf("%w: the true solution has %d rows, expected %d", ErrInvalidOption, o.solution.Len(), x.Len())
+		}
+		residual = gapOf(x, o.solution)
+	}
+
+	if o.history != nil {
+		*o.history = ConvergenceHistory{}
+	}
+	if o.stopReason != nil {
+		*o.stopReason = StopMaxIterations
+	}
+
+	return &tracker{
+		o:          o,
+		tolerance:  tolerance,
+		keepErrors: keepErrors,
+		start:      time.Now(),
+		residual:   residual,
+		x:          x,
+		bestErr:    math.Inf(1),
+		reason:     StopMaxIterations,
+	}, nil
+}
+
+// resume prepares a new run of a solve that already ran for elapsed, see Solver.Continue.
+// The time is counted from now as if the solve had never paused.
+func (t *tracker) resume(elapsed time.Duration) {
+	t.start = time.Now().Add(-elapsed)
+	t.stop(StopMaxIterations)
+}
+
+// record computes and records the error after iteration i.
+// It reports whether the solve should stop, because x is no longer finite, the error dropped to the tolerance
+// or stagnated, the time ran out or the stop channel was closed. In the first case the error to return is
+// stored in err.
+func (t *tracker) record(i int) bool {
+	t.snapshot(i)
+
+	return t.diverged(i) || t.converged(i) || t.expired(i) || t.stopped(i)
+}
+
+// stop records why the solve stopped and reports that it should
+func (t *tracker) stop(reason StopReason) bool {
+	t.reason = reason
+	if t.o.stopReason != nil {
+		*t.o.stopReason = reason
+	}
+
+	return true
+}
+
+// converged computes and records the error after iteration i.
+// It reports whether the error dropped to the tolerance or stagnated.
+//
+// Nothing is computed if neither the errors nor a history are kept, no progress report is due and
+// stagnation isn't checked. With WithEpochCheckpoints the error is only recorded after the last iteration
+// of an epoch.
+func (t *tracker) converged(i int) bool {
+	progress := t.o.progress != nil && (i+1)%t.o.progressEvery == 0
+	checkpoint := !t.o.checkpoints || t.o.epochSize < 1 || (i+1)%t.o.epochSize == 0
+	recorded := checkpoint && (t.keepErrors || t.o.history != nil || t.o.window >= 1)
+	if !recorded && !progress {
+		return false
+	}
+
+	err := t.residual()
+
+	if recorded {
+		if t.keepErrors {
+			t.errors = append(t.errors, err)
+		}
+		t.keepBest(err)
+		if h := t.o.history; h != nil {
+			h.Iterations = append(h.Iterations, i)
+			h.Residuals = append(h.Residuals, err)
+			if t.o.timing {
+				h.Elapsed = append(h.Elapsed, time.Since(t.start))
+			}
+		}
+	}
+	if progress {
+		t.o.progress(i+1, err)
+	}
+
+	if !checkpoint {
+		return false
+	}
+	if err <= t.tolerance {
+		return t.stop(StopTolerance)
+	}
+	if t.stagnated(err) {
+		return t.stop(StopStagnation)
+	}
+
+	return false
+}
+
+// keepBest copies x if err is the lowest error recorded so far and WithReturnBestIterate is used.
+// Only the recorded errors count, since the others aren't computed at every iteration.
+func (t *tracker) keepBest(err float64) {
+	if !t.o.returnBest || t.x == nil || !(err < t.bestErr) || !(t.keepErrors || t.o.history != nil) {
+		return
+	}
+
+	if t.best == nil {
+		t.best = mat.NewVecDense(t.x.Len(), nil)
+	}
+	t.best.CopyVec(t.x)
+	t.bestErr = err
+}
+
+// solution returns the iterate the solve should return, the best one if WithReturnBestIterate kept one and
+// x otherwise
+func (t *tracker) solution() *mat.VecDense {
+	if t.best != nil {
+		return t.best
+	}
+
+	return t.x
+}
+
+// stagnated reports whether the error decreased by less than the stagnation threshold over the window
+func (t *tracker) stagnated(err float64) bool {
+	if t.o.window < 1 {
+		return false
+	}
+
+	t.recent = append(t.recent, err)
+	if len(t.recent) <= t.o.window {
+		return false
+	}
+	old := t.recent[0]
+	t.recent = t.recent[1:]
+
+	return old > 0 && (old-err)/old < t.o.stagnation
+}
+
+// expired reports whether the time budget given to WithTimeout ran out. It is only checked every few iterations.
+func (t *tracker) expired(i int) bool {
+	if t.o.timeout > 0 && (i+1)%t.o.checkInterval == 0 && time.Since(t.start) >= t.o.timeout {
+		return t.stop(StopTimeout)
+	}
+
+	return false
+}
+
+// snapshot passes a copy of x to the function given to WithIterateSnapshot if one is due after iteration i
+func (t *tracker) snapshot(i int) {
+	if t.o.snapshot != nil && t.x != nil && (i+1)%t.o.snapshotEvery == 0 {
+		t.o.snapshot(i+1, mat.VecDenseCopyOf(t.x))
+	}
+}
+
+// stopped reports whether the channel given to WithStopChannel was closed. It is only checked every few iterations.
+func (t *tracker) stopped(i int) bool {
+	if t.o.stop == nil || (i+1)%t.o.checkInterval != 0 {
+		return false
+	}
+
+	select {
+	case <-t.o.stop:
+		return t.stop(StopCancelled)
+	default:
+		return false
+	}
+}
+
+// diverged reports whether x holds a NaN or an infinity after iteration i and sets err if it does.
+//
+// x is only scanned every few iterations if WithFiniteCheck is used.
+func (t *tracker) diverged(i int) bool {
+	if t.o.finiteEvery <= 0 || t.x == nil || (i+1)%t.o.finiteEvery != 0 {
+		return false
+	}
+
+	for j, value := range t.x.RawVector().Data {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			t.err = fmt.Errorf("%w: entry %d of the solution is %g after iteration %d", ErrNotFinite, j, value, i)
+			return t.stop(StopNotFinite)
+		}
+	}
+
+	return false
+}
+
+// residualOf returns a function computing the squared residual ||A*x-b||^2 of the current x
+func residualOf(A mat.Matrix, x, b *mat.VecDense) func() float64 {
+	errVec := new(mat.VecDense)
+
+	return func() float64 {
+		errVec.MulVec(A, x)
+		errVec.SubVec(errVec, b)
+		return EuclideanNormSquared(errVec)
+	}
+}
+
+// coupledResidualOf returns a function computing the squared residual ||U*V*b-y||^2 of the current b
+func coupledResidualOf(U, V mat.Matrix, b, y *mat.VecDense) func() float64 {
+	errVec := new(mat.VecDense)
+	errVec2 := new(mat.VecDense)
+
+	return func() float64 {
+		errVec.MulVec(V, b)
+		errVec2.MulVec(U, errVec)
+		errVec2.SubVec(errVec2, y)
+		return EuclideanNormSquared(errVec2)
+	}
+}
+
+// gapOf returns a function computing the squared distance ||x-solution||^2 of the current x to the true solution
+func gapOf(x, solution *mat.VecDense) func() float64 {
+	diff := new(mat.VecDense)
+
+	return func() float64 {
+		diff.SubVec(x, solution)
+		return EuclideanNormSquared(diff)
+	}
+}
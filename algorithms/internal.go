@@ -1,48 +1,254 @@
 package algorithms
 
 import (
+	"fmt"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/blas/blas64"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat/sampleuv"
 	"math"
 	"sync"
 )
 
+// EuclideanNorm returns the euclidean norm of a mat.Vector.
+//
+// @param vector : mat.Vector -- The vector for which you need the euclidean norm
+// Returns a float64 value
+func EuclideanNorm(vector mat.Vector) float64 {
+	return mat.Norm(vector, 2)
+}
+
 // EuclideanNormSquared returns the squared euclidean norm of a mat.Vector.
 //
+// The Kaczmarz updates divide by the squared norm of a row, but the algorithms divide by the norm twice instead:
+// the squared norm overflows for rows whose entries reach about 1e154.
+//
 // @param vector : mat.Vector -- The vector for which you need the squared euclidean norm
 // Returns a float64 value
 func EuclideanNormSquared(vector mat.Vector) float64 {
-	return math.Pow(mat.Norm(vector, 2), 2)
+	return math.Pow(EuclideanNorm(vector), 2)
 }
 
-// FrobeniusSquared returns the squared frobenius norm of a mat.Dense matrix
-func FrobeniusSquared(matrix *mat.Dense) float64 {
-	return math.Pow(mat.Norm(matrix, 2), 2.0)
+// FrobeniusSquared returns the squared frobenius norm of a mat.Matrix
+//
+// The norm is computed by FrobeniusNorm and squared once, so no entry is squared on its own: the result only
+// overflows to +Inf when the squared norm itself does, see FrobeniusNorm for such matrices.
+// Rows of matrices that implement mat.RawRowViewer, like mat.Dense, are read without copying.
+func FrobeniusSquared(matrix mat.Matrix) float64 {
+	norm := FrobeniusNorm(matrix)
+
+	return norm * norm
 }
 
-// GetRandomRow performs weighted sampling with the weights you provide in the rowsProb array
+// FrobeniusNorm returns the frobenius norm of a mat.Matrix without overflowing or underflowing.
 //
-// The scope of this function is to return a random row index for the RkRk and RkRek algorithms.
-// Each row probability is computed by GetRowsProbability method
-func GetRandomRow(rowsProb []float64) int {
-	//source := rand2.NewSource(uint64(time.Now().UnixNano()))
+// Every row norm is computed by the BLAS dnrm2, which scales the entries before squaring them, and the rows are
+// combined with math.Hypot, so the norm is accurate for any finite entries, even those around 1e200 whose
+// squares overflow. Rows of matrices that implement mat.RawRowViewer, like mat.Dense, are read without copying.
+func FrobeniusNorm(matrix mat.Matrix) float64 {
+	rows, cols := matrix.Dims()
+	buf := make([]float64, cols)
+
+	norm := 0.0
+	for i := 0; i < rows; i++ {
+		norm = math.Hypot(norm, EuclideanNorm(rowOf(matrix, i, buf)))
+	}
+
+	return norm
+}
 
-	index, _ := sampleuv.NewWeighted(rowsProb, nil).Take()
+// GetRandomRow performs weighted sampling with the weights you provide in the rowsProb array
+//
+// Building the sampler costs as much as a pass over the weights, so this is meant for weights that change at every
+// iteration, like those of GreedyRandomizedKaczmarz. Fixed probabilities, like those computed by the
+// GetRowsProbability function, should be sampled through an AliasSampler built once.
+// The index is drawn from src, or from the global source if src is nil.
+func GetRandomRow(rowsProb []float64, src rand.Source) int {
+	index, _ := sampleuv.NewWeighted(rowsProb, src).Take()
 
 	return index
 }
 
+// RowProbabilities returns the probability with which the solvers draw every row of A by default.
+//
+// The probability of row i is ||A_i||^2/||A||_F^2, its squared euclidean norm divided by the squared frobenius norm
+// of A, so the probabilities sum to 1 up to rounding errors. Rows that are entirely zero get a zero probability
+// and are never drawn, and if the whole matrix is zero every probability is zero. The probabilities don't take
+// WithRowProbabilities, WithRowScaling or WithSamplingStrategy into account.
+func RowProbabilities(A mat.Matrix) []float64 {
+	rows, _ := A.Dims()
+	probs := make([]float64, rows)
+	norms := make([]float64, rows)
+
+	rowsProbability(probs, norms, A)
+
+	return probs
+}
+
 // GetRowsProbability computes the probabilities of choosing a random row from a matrix
 //
 // The probability is computed as the squared euclidean norm of the row divided by the
 // squared frobenius norm of the matrix
 //
-// Since this method is intended to be used with the RkRk and RkRek algorithms which require computing
-// the probabilities of many rows, this method requires a WaitGroup and has multithreaded behaviour.
-func GetRowsProbability(probVector []float64, frobenius float64, matrix *mat.Dense, rownum int, group *sync.WaitGroup) {
-	for i := 0; i < rownum; i++ {
-		probVector[i] = EuclideanNormSquared(matrix.RowView(i)) / frobenius
-	}
+// Rows that are entirely zero get a zero probability, so they are never chosen and the algorithms never divide
+// by their zero norm. If the whole matrix is zero every probability is zero.
+//
+// The euclidean norm of each row is stored in normsVector so that the algorithms don't have to
+// compute it again every time the row is chosen. The frobenius norm of the matrix, which they combine into,
+// is stored in frobenius, so the norm of the matrix comes for free.
+//
+// The norms are computed without overflowing, see fillProbabilities, so the probabilities and the norms are right
+// even when the squared norms would overflow to +Inf. The algorithms divide by a norm twice instead of dividing by
+// its square for the same reason.
+//
+// Since this function is intended to be used with the RkRk and RkRek algorithms which require computing
+// the probabilities of many rows, it requires a WaitGroup and has multithreaded behaviour.
+// The solvers that only need the probabilities of a single matrix call rowsProbability directly instead.
+func GetRowsProbability(probVector, normsVector []float64, frobenius *float64, matrix mat.Matrix, rownum int, group *sync.WaitGroup) {
+	*frobenius = rowsProbability(probVector[:rownum], normsVector[:rownum], matrix)
 
 	group.Done()
 }
+
+// rowsProbability is GetRowsProbability without the WaitGroup: it stores the probability and the euclidean norm
+// of every row of matrix and returns its frobenius norm
+func rowsProbability(probVector, normsVector []float64, matrix mat.Matrix) float64 {
+	_, cols := matrix.Dims()
+	buf := make([]float64, cols)
+
+	return fillProbabilities(probVector, normsVector, func(i int) float64 {
+		return EuclideanNorm(rowOf(matrix, i, buf))
+	})
+}
+
+// fillProbabilities stores the euclidean norm and the probability of every row of a matrix whose norms are
+// given by rowNorm, and returns its frobenius norm.
+//
+// The row norms are combined with math.Hypot and every probability is computed as (||A_i||/||A||_F)^2, so that
+// neither the norms nor the probabilities overflow or underflow when the squared norms would. Only zero rows get a
+// zero probability.
+func fillProbabilities(probVector, normsVector []float64, rowNorm func(i int) float64) float64 {
+	norm := 0.0
+	for i := range normsVector {
+		normsVector[i] = rowNorm(i)
+		norm = math.Hypot(norm, normsVector[i])
+	}
+
+	for i := range probVector {
+		probVector[i] = 0
+		if normsVector[i] > 0 {
+			ratio := normsVector[i] / norm
+			probVector[i] = ratio * ratio
+		}
+	}
+
+	return norm
+}
+
+// rowOf returns the i-th row of a matrix as a vector.
+//
+// Rows of matrices that implement mat.RawRowViewer, like mat.Dense, are viewed without copying.
+// Rows of any other matrix are copied into buf, which must be as long as a row and is overwritten by the next call.
+// A viewed row shares the storage of the caller's matrix, so the vector must only ever be read.
+func rowOf(matrix mat.Matrix, i int, buf []float64) *mat.VecDense {
+	if viewer, ok := matrix.(mat.RawRowViewer); ok {
+		return mat.NewVecDense(len(buf), viewer.RawRowView(i))
+	}
+
+	return mat.NewVecDense(len(buf), mat.Row(buf, i, matrix))
+}
+
+// rowReader reads the rows of a matrix into the same vector, so reading a row in the iterations allocates nothing
+type rowReader struct {
+	matrix mat.Matrix
+	viewer mat.RawRowViewer
+	buf    []float64
+	row    *mat.VecDense
+}
+
+// newRowReader prepares the reading of the rows of matrix, see rowOf
+func newRowReader(matrix mat.Matrix) *rowReader {
+	_, cols := matrix.Dims()
+	viewer, _ := matrix.(mat.RawRowViewer)
+
+	return &rowReader{
+		matrix: matrix,
+		viewer: viewer,
+		buf:    make([]float64, cols),
+		row:    mat.NewVecDense(cols, nil),
+	}
+}
+
+// at returns the i-th row of the matrix, as rowOf does. The vector is overwritten by the next call and, like the
+// rows of rowOf, must only be read.
+func (r *rowReader) at(i int) *mat.VecDense {
+	data := r.buf
+	if r.viewer != nil {
+		data = r.viewer.RawRowView(i)
+	} else {
+		mat.Row(data, i, r.matrix)
+	}
+	r.row.SetRawVector(blas64.Vector{N: len(r.buf), Inc: 1, Data: data})
+
+	return r.row
+}
+
+// applyRowProbabilities replaces the probabilities in probVector with custom ones, normalized to sum to 1.
+//
+// Rows whose norm in normsVector is zero keep a zero probability. An ErrInvalidOption is returned if
+// custom doesn't hold one non-negative entry per row or puts no weight on a non-zero row.
+func applyRowProbabilities(probVector, normsVector, custom []float64) error {
+	if err := checkProbabilities(custom, len(probVector)); err != nil {
+		return err
+	}
+
+	sum := 0.0
+	for row, prob := range custom {
+		if normsVector[row] > 0 {
+			sum += prob
+		}
+	}
+	if sum == 0 {
+		return fmt.Errorf("%w: the row probabilities are zero on every non-zero row", ErrInvalidOption)
+	}
+	if math.IsInf(sum, 1) {
+		return fmt.Errorf("%w: the sum of the row probabilities overflows", ErrInvalidOption)
+	}
+
+	for row, prob := range custom {
+		if normsVector[row] > 0 {
+			probVector[row] = prob / sum
+		} else {
+			probVector[row] = 0
+		}
+	}
+
+	return nil
+}
+
+// dotOver returns a*v/norm, where norm is the euclidean norm of a.
+//
+// The product is computed by the BLAS, unless it overflows: then the entries of a are divided by norm before
+// being multiplied, so that the product of a row of a large matrix with a vector of the same scale, like the
+// residual or z in RandomizedExtendedKaczmarz, stays finite whenever the result is.
+func dotOver(a, v mat.Vector, norm float64) float64 {
+	if dot := mat.Dot(a, v); !math.IsInf(dot, 0) && !math.IsNaN(dot) {
+		return dot / norm
+	}
+
+	sum := 0.0
+	for j := 0; j < a.Len(); j++ {
+		sum += a.AtVec(j) / norm * v.AtVec(j)
+	}
+
+	return sum
+}
+
+// clampNegative sets the negative entries of x to zero, projecting it onto the nonnegative orthant
+func clampNegative(x []float64) {
+	for j, value := range x {
+		if value < 0 {
+			x[j] = 0
+		}
+	}
+}
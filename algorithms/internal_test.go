@@ -0,0 +1,197 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// largeSystem returns a consistent 3x2 system whose entries are around 1e200, so that their squares overflow,
+// and its solution [1 2]
+func largeSystem() (*mat.Dense, *mat.VecDense, []float64) {
+	A := mat.NewDense(3, 2, []float64{3, 1, 1, 2, 1, 1})
+	A.Scale(1e200, A)
+	b := mat.NewVecDense(3, nil)
+	b.MulVec(A, mat.NewVecDense(2, []float64{1, 2}))
+
+	return A, b, []float64{1, 2}
+}
+
+func checkSolution(t *testing.T, name string, x []float64, want []float64) {
+	t.Helper()
+	for j := range want {
+		if math.Abs(x[j]-want[j]) > 1e-6 {
+			t.Errorf("%s: x = %v, want %v", name, x, want)
+			return
+		}
+	}
+}
+
+func TestLargeEntries(t *testing.T) {
+	A, b, want := largeSystem()
+
+	solvers := []struct {
+		name  string
+		solve func() (mat.VecDense, []float64, error)
+	}{
+		{"RandomizedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(1))
+		}},
+		{"RandomizedExtendedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return RandomizedExtendedKaczmarz(A, b, 2000, 0, false, WithSeed(1))
+		}},
+		{"RandomizedGaussSeidel", func() (mat.VecDense, []float64, error) {
+			return RandomizedGaussSeidel(A, b, 2000, 0, false, WithSeed(1))
+		}},
+		{"BlockRandomizedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return BlockRandomizedKaczmarz(A, b, 2, 2000, 0, false, WithSeed(1))
+		}},
+		{"GreedyRandomizedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return GreedyRandomizedKaczmarz(A, b, 0.5, 2000, 0, false, WithSeed(1))
+		}},
+		{"WithRowScaling", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(1), WithRowScaling([]float64{1, 2, 3}))
+		}},
+		{"WithTikhonov", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(1), WithTikhonov(1e-300))
+		}},
+	}
+
+	for _, solver := range solvers {
+		x, _, err := solver.solve()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", solver.name, err)
+			continue
+		}
+		checkSolution(t, solver.name, x.RawVector().Data, want)
+	}
+}
+
+func TestLargeEntriesCmplx(t *testing.T) {
+	A := mat.NewCDense(2, 2, []complex128{3e200, 1e200i, 1e200, 2e200})
+	want := []complex128{1, 2i}
+	b := []complex128{cmplxDot(A.RawCMatrix().Data[:2], want), cmplxDot(A.RawCMatrix().Data[2:], want)}
+
+	x, _, err := RandomizedKaczmarzCmplx(A, b, 2000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for j := range want {
+		if cmplx.Abs(x[j]-want[j]) > 1e-6 {
+			t.Fatalf("x = %v, want %v", x, want)
+		}
+	}
+}
+
+func TestRowProbabilitiesLargeEntries(t *testing.T) {
+	A, _, _ := largeSystem()
+
+	probs := RowProbabilities(A)
+	want := []float64{10.0 / 17, 5.0 / 17, 2.0 / 17}
+	for i := range want {
+		if math.Abs(probs[i]-want[i]) > 1e-12 {
+			t.Fatalf("probabilities = %v, want %v", probs, want)
+		}
+	}
+}
+
+func TestFrobeniusNorm(t *testing.T) {
+	A, _, _ := largeSystem()
+
+	if got, want := FrobeniusNorm(A), math.Sqrt(17)*1e200; math.Abs(got-want) > 1e-12*want {
+		t.Errorf("FrobeniusNorm = %g, want %g", got, want)
+	}
+}
+
+func TestEuclideanNorm(t *testing.T) {
+	vectors := []struct {
+		x       []float64
+		norm    float64
+		squared float64
+	}{
+		{[]float64{3, 4}, 5, 25},
+		{[]float64{1, 2, 2}, 3, 9},
+		{[]float64{-2}, 2, 4},
+		{[]float64{0, 0, 0}, 0, 0},
+	}
+
+	for _, v := range vectors {
+		x := mat.NewVecDense(len(v.x), v.x)
+		if got := EuclideanNorm(x); math.Abs(got-v.norm) > 1e-12 {
+			t.Errorf("EuclideanNorm(%v) = %g, want %g", v.x, got, v.norm)
+		}
+		if got := EuclideanNormSquared(x); math.Abs(got-v.squared) > 1e-12 {
+			t.Errorf("EuclideanNormSquared(%v) = %g, want %g", v.x, got, v.squared)
+		}
+	}
+}
+
+func TestRowProbabilities(t *testing.T) {
+	A := mat.NewDense(4, 2, []float64{3, 4, 0, 0, 1, 0, 0, -2})
+
+	probs := RowProbabilities(A)
+	sum := 0.0
+	for _, prob := range probs {
+		sum += prob
+	}
+	if math.Abs(sum-1) > 1e-15 {
+		t.Errorf("the probabilities %v sum to %g, want 1", probs, sum)
+	}
+
+	// The squared norms are 25, 0, 1 and 4
+	for i, squared := range []float64{25, 0, 1, 4} {
+		if want := squared / 30; math.Abs(probs[i]-want) > 1e-15 {
+			t.Errorf("probability of row %d = %g, want %g", i, probs[i], want)
+		}
+	}
+}
+
+func TestIllScaledRows(t *testing.T) {
+	// The rows of a consistent system scaled over 240 orders of magnitude, so that their squared norms, and the
+	// probabilities of norm sampling, underflow and overflow
+	A, b, want := smallSystem()
+	scales := []float64{1e-120, 1e-40, 1e40, 1e120}
+	d := make([]float64, len(scales))
+	for i, scale := range scales {
+		row := A.RawRowView(i)
+		for j := range row {
+			row[j] *= scale
+		}
+		b.SetVec(i, b.AtVec(i)*scale)
+		d[i] = 1 / scale
+	}
+
+	// Sampled by norm, the last row is drawn almost every time and the others next to never
+	probs := RowProbabilities(A)
+	if probs[3] < 1-1e-12 || probs[0] != 0 {
+		t.Errorf("probabilities = %v, want about [0 0 0 1]", probs)
+	}
+
+	solvers := []struct {
+		name  string
+		solve func() (mat.VecDense, []float64, error)
+	}{
+		{"WithRowScaling", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(1), WithRowScaling(d))
+		}},
+		{"WithRowNormalization", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(1), WithRowNormalization(true))
+		}},
+		{"AveragedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return AveragedKaczmarz(A, b, 1, 2000, 0, false, WithSeed(1), WithRowNormalization(true))
+		}},
+		{"Cyclic", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 2000, 0, false, WithSamplingStrategy(Cyclic), WithRowNormalization(true))
+		}},
+	}
+
+	for _, solver := range solvers {
+		x, _, err := solver.solve()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", solver.name, err)
+			continue
+		}
+		checkSolution(t, solver.name, x.RawVector().Data, want)
+	}
+}
@@ -0,0 +1,131 @@
+package algorithms
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// RandomizedSparseKaczmarz returns a sparse solution of a consistent system A*x=b using the randomized sparse
+// Kaczmarz algorithm of Lorenz, Wenger, Schöpfer and Wright.
+//
+// It runs RandomizedKaczmarz on an auxiliary dual vector z and keeps x = S_lambda(z), the soft thresholding of z
+// that sets the entries with |z_j| <= lambda to zero and shrinks the others by lambda. It converges to the solution
+// of min lambda*||x||_1 + 1/2*||x||^2 subject to A*x=b, which for a large enough lambda is the sparsest one, as
+// needed in compressed sensing.
+//
+// Parameters:
+// A is a mat.Matrix representing the system, usually with fewer rows than columns.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// lambda is a float64 setting how strongly sparsity is promoted. A lambda of 0 is plain RandomizedKaczmarz.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if lambda is
+// negative or the row probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// A small lambda gives a solution that is only somewhat sparse, while the larger lambda the slower the
+// convergence; a lambda a few times the largest entry expected in the solution is a good start.
+// WithInitialGuess sets the starting dual vector z, so x starts from its soft thresholding.
+func RandomizedSparseKaczmarz(A mat.Matrix, b *mat.VecDense, lambda float64, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if lambda < 0 || math.IsNaN(lambda) {
+		return mat.VecDense{}, nil, fmt.Errorf("%w: lambda must be non-negative, got %g", ErrInvalidOption, lambda)
+	}
+
+	z, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	x := mat.NewVecDense(colsA, nil)
+	softThreshold(x.RawVector().Data, z.RawVector().Data, lambda)
+
+	// Reader of the rows of A, which reuses the same vector for every row
+	readerA := newRowReader(A)
+
+	track, err := newTracker(o, tolerance, keepErrors, x, residualOf(A, x, b))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each row of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+
+	frobeniusA := rowsProbability(probsA, normsA, A)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
+	// Projecting z onto the hyperplane of a randomly chosen row, measured at x, and shrinking z into x
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+
+		chosenA := readerA.at(randA)
+
+		z.AddScaledVec(
+			z,
+			o.relaxation*((b.AtVec(randA)-mat.Dot(chosenA, x))/normsA[randA])/normsA[randA],
+			chosenA)
+		softThreshold(x.RawVector().Data, z.RawVector().Data, lambda)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
+
+// softThreshold sets x to S_lambda(z), moving every entry of z towards zero by lambda and zeroing those within lambda
+func softThreshold(x, z []float64, lambda float64) {
+	for j, value := range z {
+		switch {
+		case value > lambda:
+			x[j] = value - lambda
+		case value < -lambda:
+			x[j] = value + lambda
+		default:
+			x[j] = 0
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+func TestRandomizedSparseKaczmarz(t *testing.T) {
+	// An underdetermined gaussian system with a 4-sparse solution
+	const rows, cols = 40, 100
+	rnd := rand.New(rand.NewSource(1))
+	A := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			A.Set(i, j, rnd.NormFloat64())
+		}
+	}
+	want := mat.NewVecDense(cols, nil)
+	for j, value := range map[int]float64{7: 1.5, 23: -2, 61: 1, 88: -1.2} {
+		want.SetVec(j, value)
+	}
+	b := mat.NewVecDense(rows, nil)
+	b.MulVec(A, want)
+
+	x, _, err := RandomizedSparseKaczmarz(A, b, 5, 200_000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for j := 0; j < cols; j++ {
+		if (want.AtVec(j) != 0) != (math.Abs(x.AtVec(j)) > 1e-3) {
+			t.Errorf("x[%d] = %g, want %g", j, x.AtVec(j), want.AtVec(j))
+		}
+	}
+	gap := mat.NewVecDense(cols, nil)
+	gap.SubVec(&x, want)
+	if norm := EuclideanNorm(gap); norm > 1e-4 {
+		t.Errorf("|x-x_true| = %g, want the sparse solution", norm)
+	}
+}
+
+func TestRandomizedSparseKaczmarzWithoutThreshold(t *testing.T) {
+	A, b, _ := smallSystem()
+
+	// With lambda = 0, x is z and the iteration is RandomizedKaczmarz
+	thresholded, _, err := RandomizedSparseKaczmarz(A, b, 0, 300, 0, false, WithSeed(4))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	plain, _, _ := RandomizedKaczmarz(A, b, 300, 0, false, WithSeed(4))
+	if !mat.EqualApprox(&thresholded, &plain, 1e-12) {
+		t.Errorf("x = %v with lambda = 0, want the RandomizedKaczmarz %v", thresholded.RawVector().Data, plain.RawVector().Data)
+	}
+}
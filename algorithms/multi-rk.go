@@ -0,0 +1,113 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// RandomizedKaczmarzMulti returns the solution of a consistent system A*X=B with many right-hand sides using the
+// randomized Kaczmarz algorithm.
+//
+// Every column of X is the solution of A*x=b for the matching column of B. Instead of solving them one by one,
+// every chosen row of A updates all the columns of X at once with the matching row of B, so the rows are
+// sampled once for all the right-hand sides.
+//
+// Parameters:
+// A is a mat.Matrix representing the system. mat.Dense rows are read without copying.
+// B is a mat.Dense matrix whose columns are the expected outputs for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the matrix X that solves A*X=B and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if B doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations and tolerance behave as in RandomizedKaczmarz.
+// The error is the squared frobenius norm of A*X-B, i.e. the sum of the squared residuals of all the columns.
+// WithInitialGuess only applies to a single right-hand side and is ignored.
+func RandomizedKaczmarzMulti(A mat.Matrix, B *mat.Dense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.Dense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkRows(B, "B", rowsA, "A"); err != nil {
+		return mat.Dense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.Dense{}, nil, err
+	}
+	if err := o.checkFinite(B, "B"); err != nil {
+		return mat.Dense{}, nil, err
+	}
+	_, colsB := B.Dims()
+
+	X := mat.NewDense(colsA, colsB, nil)
+
+	// Readers of the rows of A and B, which reuse the same vector for every row, and the residual of the chosen
+	// row for every column
+	readerA := newRowReader(A)
+	readerB := newRowReader(B)
+	residual := mat.NewVecDense(colsB, nil)
+
+	errMat := new(mat.Dense)
+	track, err := newTracker(o, tolerance, keepErrors, nil, func() float64 {
+		errMat.Mul(A, X)
+		errMat.Sub(errMat, B)
+		return FrobeniusSquared(errMat)
+	})
+	if err != nil {
+		return mat.Dense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each row of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+
+	frobeniusA := rowsProbability(probsA, normsA, A)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.Dense{}, nil, err
+	}
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.Dense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.Dense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
+	// Projecting every column of X onto the hyperplane of a randomly chosen row
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+
+		chosenA := readerA.at(randA)
+
+		residual.MulVec(X.T(), chosenA)
+		residual.SubVec(readerB.at(randA), residual)
+
+		// The residual is divided by the norm of the row once here and once in the update, the squared norm of
+		// a large row would overflow
+		residual.ScaleVec(1/normsA[randA], residual)
+		X.RankOne(X, o.relaxation/normsA[randA], chosenA, residual)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	return *X, track.errors, track.err
+}
@@ -0,0 +1,37 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+func TestRandomizedKaczmarzMulti(t *testing.T) {
+	A, B, want := randomSystem(rand.New(rand.NewSource(1)), 30, 4)
+
+	X, errs, err := RandomizedKaczmarzMulti(A, B, 2000, 0, true, WithSeed(7))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(errs) == 0 || errs[len(errs)-1] > 1e-20 {
+		t.Errorf("the last of the %d errors isn't close to zero", len(errs))
+	}
+	if !mat.EqualApprox(&X, want, 1e-8) {
+		t.Errorf("X = %v, want %v", mat.Formatted(&X), mat.Formatted(want))
+	}
+
+	// Every column follows the same rows as a single right-hand side solve with the same seed
+	for j := 0; j < 4; j++ {
+		b := mat.NewVecDense(30, mat.Col(nil, j, B))
+		x, _, err := RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(7))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for i := 0; i < 4; i++ {
+			if math.Abs(x.AtVec(i)-X.At(i, j)) > 1e-12 {
+				t.Fatalf("column %d = %v, want the single solve %v", j, mat.Col(nil, j, &X), x.RawVector().Data)
+			}
+		}
+	}
+}
@@ -0,0 +1,668 @@
+package algorithms
+
+import (
+	"fmt"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"log"
+	"math"
+	"time"
+)
+
+// options holds the optional settings shared by the solvers of this package
+type options struct {
+	seed          uint64
+	seeded        bool
+	checkInterval int
+	relaxation    float64
+	partition     [][]int
+	history       *ConvergenceHistory
+	timing        bool
+	initialGuess  *mat.VecDense
+	intermediate  *mat.VecDense
+	rowWeights    []float64
+	momentum      float64
+	rowProbs      []float64
+	rowScaling    []float64
+	normalize     bool
+	progressEvery int
+	progress      func(iter int, residual float64)
+	logger        *log.Logger
+	sampled       []sampledRows
+	timeout       time.Duration
+	metric        ErrorMetric
+	solution      *mat.VecDense
+	strategy      SamplingStrategy
+	rowSampler    RowSampler
+	adaptiveEvery int
+	finiteEvery   int
+	window        int
+	stagnation    float64
+	stopReason    *StopReason
+	finalResidual *mat.VecDense
+	epochs        float64
+	checkpoints   bool
+	returnBest    bool
+	// epochSize is the number of iterations of an epoch of the running solver, see epochIterations
+	epochSize     int
+	nonnegative   bool
+	lambda        float64
+	stop          <-chan struct{}
+	snapshotEvery int
+	snapshot      func(iter int, x mat.Vector)
+	incremental   bool
+	recentRows    int
+	gramRows      int
+	rankDeficient *bool
+	// skipInputCheck is the negation of WithInputCheck, so that the check is on by default
+	skipInputCheck bool
+}
+
+// Option configures an optional setting of a solver
+type Option func(*options)
+
+// ErrorMetric chooses what the errors returned by the solvers measure, see WithErrorMetric
+type ErrorMetric int
+
+const (
+	// ErrorMetricResidual measures the squared residual ||b-A*x||^2, or ||y-U*V*b||^2 for RkRk and RkRek.
+	// It is the default since it needs nothing but the system.
+	ErrorMetricResidual ErrorMetric = iota
+	// ErrorMetricSolutionGap measures the squared distance ||x-x*||^2 of the returned vector to the true solution x*
+	// given to WithTrueSolution. For RkRk and RkRek the returned vector is b.
+	ErrorMetricSolutionGap
+)
+
+// WithSeed makes the solver draw its random rows from a single source seeded with seed.
+//
+// Two runs on the same system with the same seed choose the same rows and produce identical results.
+// Without this option the global random source is used.
+func WithSeed(seed uint64) Option {
+	return func(o *options) {
+		o.seed = seed
+		o.seeded = true
+	}
+}
+
+// WithSubstream makes the solver draw its random rows from substream run of the master seed, for experiments
+// made of many runs that must be independent but reproducible as a whole.
+//
+// The seed of run k is derived from master and k with the SplitMix64 mixer, so run k gets the same seed whatever
+// the other runs do, and runs of the same master seed draw unrelated rows. It is WithSeed(SubstreamSeed(master, run)).
+func WithSubstream(master uint64, run int) Option {
+	return WithSeed(SubstreamSeed(master, run))
+}
+
+// SubstreamSeed returns the seed of substream run of the master seed, see WithSubstream
+func SubstreamSeed(master uint64, run int) uint64 {
+	z := master + uint64(run+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return z ^ (z >> 31)
+}
+
+// WithCheckInterval sets every how many iterations a solver checks whether it was cancelled or ran out of time.
+//
+// Smaller intervals stop sooner but cost more. The default is 100; values below 1 are ignored.
+func WithCheckInterval(iterations int) Option {
+	return func(o *options) {
+		if iterations > 0 {
+			o.checkInterval = iterations
+		}
+	}
+}
+
+// WithRelaxation scales every Kaczmarz projection step by omega.
+//
+// omega = 1 is the plain projection and the default. 1 < omega < 2 is over-relaxation, which steps past
+// the hyperplane of the chosen row, and 0 < omega < 1 is under-relaxation, which stops short of it.
+// Values outside (0, 2) generally make the iteration diverge.
+func WithRelaxation(omega float64) Option {
+	return func(o *options) {
+		o.relaxation = omega
+	}
+}
+
+// WithPartition makes BlockRandomizedKaczmarz choose among the given blocks of row indices instead of
+// consecutive blocks of rows.
+//
+// Every block must be non-empty and hold valid row indices. The blocks don't need to cover all the rows,
+// but rows outside every block are never projected on.
+func WithPartition(blocks [][]int) Option {
+	return func(o *options) {
+		o.partition = blocks
+	}
+}
+
+// WithHistory makes the solver record its convergence into history.
+//
+// The history is reset at the start of the solve. Recording a history computes the residual after every iteration,
+// and checks it against the tolerance, even if the solver was not asked to keep the errors.
+func WithHistory(history *ConvergenceHistory) Option {
+	return func(o *options) {
+		o.history = history
+	}
+}
+
+// WithTiming makes the solver also record the elapsed time of every residual in the history given to WithHistory
+func WithTiming() Option {
+	return func(o *options) {
+		o.timing = true
+	}
+}
+
+// WithInitialGuess makes the solver start from a copy of x0 instead of the zero vector.
+//
+// Warm-starting from the solution of a related system, e.g. the previous step of a time-stepping scheme,
+// can cut the number of iterations dramatically. x0 must have one row per column of the system matrix,
+// which for RkRk and RkRek is V. A nil x0 keeps the zero start.
+func WithInitialGuess(x0 *mat.VecDense) Option {
+	return func(o *options) {
+		o.initialGuess = x0
+	}
+}
+
+// WithInitialIntermediate makes RkRk and RkRek start the intermediate solution x of U*x=y from a copy of x0
+// instead of the zero vector.
+//
+// x0 must have one row per column of U. A nil x0 keeps the zero start. The other solvers ignore this option.
+func WithInitialIntermediate(x0 *mat.VecDense) Option {
+	return func(o *options) {
+		o.intermediate = x0
+	}
+}
+
+// WithAveragingWeights sets the weight of every row of A in the average taken by AveragedKaczmarz.
+//
+// weights must hold one non-negative entry per row of A. The projections of the rows sampled at an iteration
+// are scaled by their weights before being averaged, so rows that are trusted less, e.g. noisier measurements,
+// can be given a smaller weight. By default every row has a weight of 1. The other solvers ignore this option.
+func WithAveragingWeights(weights []float64) Option {
+	return func(o *options) {
+		o.rowWeights = weights
+	}
+}
+
+// WithMomentum adds a heavy ball momentum term to every RandomizedKaczmarz step, following Loizou and Richtarik.
+//
+// The update becomes x_{k+1} = x_k + step + beta*(x_k - x_{k-1}), which can cut the number of iterations on
+// ill-conditioned systems. The iteration is only stable for 0 <= beta < 1; values around 0.3-0.5 are a good start.
+// The default of 0 is the plain iteration. The other solvers ignore this option.
+func WithMomentum(beta float64) Option {
+	return func(o *options) {
+		o.momentum = beta
+	}
+}
+
+// WithRecentRows makes every RandomizedKaczmarz step project x onto the intersection of the hyperplanes of the
+// last k rows drawn instead of the hyperplane of the last one alone.
+//
+// Projecting onto one row can undo much of the progress made on the previous ones when the rows are correlated,
+// which makes plain Kaczmarz crawl. Keeping a rolling window of the last k rows, orthonormalized by Gram-Schmidt
+// at every step, the projection satisfies all of them at once, like a block method whose block slides by one row
+// per iteration. Rows that are drawn twice or depend on the others in the window are left out of the projection.
+// A step costs about k*k times as much as a plain one and the window needs k*cols memory, so small windows of
+// 2 to 8 rows are the sweet spot. A k of 1 or less, the default, is the plain iteration. It can't be combined
+// with WithTikhonov, and the residual is computed from scratch even with WithIncrementalResidual. The other
+// solvers ignore this option.
+func WithRecentRows(k int) Option {
+	return func(o *options) {
+		o.recentRows = k
+	}
+}
+
+// WithNonnegativity makes RandomizedKaczmarz and RandomizedKaczmarzSparse look for a solution with no negative entry,
+// as needed for concentrations or the densities of tomography.
+//
+// This is the simple projected Kaczmarz scheme: after every row projection, and on the starting point, the negative
+// entries of x are set to zero, which projects x onto the nonnegative orthant. If the system has a nonnegative
+// solution the iteration converges to one; otherwise it settles near a nonnegative point with a small residual,
+// but it isn't guaranteed to be the nonnegative least-squares solution. The other solvers ignore this option.
+func WithNonnegativity(enabled bool) Option {
+	return func(o *options) {
+		o.nonnegative = enabled
+	}
+}
+
+// WithTikhonov makes RandomizedKaczmarz solve the Tikhonov regularized problem min ||A*x-b||^2 + lambda*||x||^2,
+// i.e. ridge regression with penalty lambda, whose solution is x = (A^T*A + lambda*I)^-1 * A^T*b.
+//
+// On ill-posed problems with noisy b plain Kaczmarz first approaches the solution and then drifts away as it fits
+// the noise; the penalty keeps x bounded at the cost of a bias towards zero. The solution is the minimum norm
+// solution of the consistent system [A sqrt(lambda)*I]*[x; u] = b, so each row projection also moves one entry of
+// an auxiliary vector u, one per row of A, and no augmented matrix is built. Like the minimum norm solution this
+// needs x to start from zero. The errors are still the residuals of A*x=b, which don't drop to zero with a
+// positive lambda; WithErrorMetric and WithTrueSolution can track the distance to a known ridge solution instead.
+// A lambda of 0, the default, is the plain iteration. The other solvers ignore this option.
+func WithTikhonov(lambda float64) Option {
+	return func(o *options) {
+		o.lambda = lambda
+	}
+}
+
+// WithRowProbabilities makes the solver sample the rows of A with the given probabilities instead of
+// proportionally to their squared norms.
+//
+// probabilities must hold one non-negative entry per row of A and is normalized internally, so uniform sampling
+// is a slice of ones. Rows that are entirely zero are never chosen, whatever their probability.
+// RandomizedKaczmarz, RandomizedKaczmarzSparse, RandomizedExtendedKaczmarz and AveragedKaczmarz use this option
+// for the rows of A; the other solvers ignore it.
+func WithRowProbabilities(probabilities []float64) Option {
+	return func(o *options) {
+		o.rowProbs = probabilities
+	}
+}
+
+// WithRowScaling preconditions the system with the diagonal matrix D=diag(d), solving D*A*x=D*b instead of A*x=b.
+//
+// Badly scaled systems, whose rows have very different norms, sample their large rows far too often. Since the
+// projection onto the hyperplane of a row doesn't change when the row and its entry of b are scaled together,
+// the scaling only changes the sampling: row i is sampled with probability proportional to d[i]^2*||A_i||^2
+// instead of ||A_i||^2. The scaled matrix is never stored and the errors are still the residuals of A*x=b.
+// For example, d[i] = 1/||A_i|| samples the rows uniformly.
+//
+// d must hold one finite entry per row of A and can't be combined with WithRowProbabilities. Rows with a zero
+// scaling are never chosen. The solvers that support WithRowProbabilities support this option; the others ignore it.
+func WithRowScaling(d []float64) Option {
+	return func(o *options) {
+		o.rowScaling = d
+	}
+}
+
+// WithRowNormalization scales every row of A and its entry of b to unit norm before solving, i.e. it is
+// WithRowScaling with d[i] = 1/||A_i||.
+//
+// As with WithRowScaling the scaled system is never stored: the projections divide by the row norms
+// computed once at setup, so a unit norm would save nothing in the iterations, and the projection onto a row
+// doesn't depend on its scale anyway. On a consistent system this changes nothing mathematically, the solution
+// is the same and every projection moves x the same way; only the rows are sampled uniformly instead of
+// proportionally to their squared norms. It can't be combined with WithRowProbabilities or WithRowScaling.
+// The solvers that support WithRowProbabilities support this option; the others ignore it.
+func WithRowNormalization(enabled bool) Option {
+	return func(o *options) {
+		o.normalize = enabled
+	}
+}
+
+// WithProgress makes the solver call fn every `every` iterations with the number of iterations performed so far
+// and the current squared residual, the same value as the errors returned by the solvers.
+//
+// fn is called synchronously from the solver, so the calls are ordered and the solve waits for fn to return.
+// The residual is computed for these calls even if the solver was not asked to keep the errors, and it is
+// checked against the tolerance. Values of every below 1 are treated as 1.
+func WithProgress(every int, fn func(iter int, residual float64)) Option {
+	return func(o *options) {
+		if every < 1 {
+			every = 1
+		}
+		o.progressEvery = every
+		o.progress = fn
+	}
+}
+
+// WithIterateSnapshot makes the solver call fn every `every` iterations with the number of iterations performed so
+// far and a copy of the current solution, e.g. to animate how it evolves.
+//
+// fn is called synchronously from the solver and owns the copy, which the solver never touches again. Every call
+// copies the whole solution, which costs as much as an iteration or more, so a large every keeps the solve fast.
+// Snapshots are off by default. RandomizedKaczmarzMulti and RandomizedKaczmarzCmplx ignore this option.
+// Values of every below 1 are treated as 1.
+func WithIterateSnapshot(every int, fn func(iter int, x mat.Vector)) Option {
+	return func(o *options) {
+		if every < 1 {
+			every = 1
+		}
+		o.snapshotEvery = every
+		o.snapshot = fn
+	}
+}
+
+// WithIncrementalResidual makes RandomizedKaczmarz update the residual b-A*x at every iteration instead of
+// computing it from scratch whenever an error is recorded.
+//
+// A projection moves x along a row a_i of A, which changes the residual by a multiple of A*a_i, a column of the
+// Gram matrix A*A^T. With the Gram matrix computed once at the start of the solve, an update costs O(rows) instead
+// of the O(rows*cols) of a full residual, so keeping the errors at every iteration stays cheap. The Gram matrix
+// needs rows*rows memory and costs rows*rows*cols to build, which only pays off on solves long compared to the
+// rows and when the errors are kept. To stop rounding errors from building up, the residual is computed from
+// scratch once per epoch. The projection onto a row then reads b_i-a_i*x off the residual instead of computing
+// the dot product. WithMomentum and WithNonnegativity move x off the rows, so with them the residual is always
+// computed from scratch. See WithGramCache to build the Gram matrix once for many solves. The other solvers
+// ignore this option.
+func WithIncrementalResidual(enabled bool) Option {
+	return func(o *options) {
+		o.incremental = enabled
+	}
+}
+
+// WithGramCache makes NewSolver compute the Gram matrix A*A^T once if A has at most maxRows rows, and every solve
+// of the Solver update the residual with it as WithIncrementalResidual does, without building it again.
+//
+// It is meant for solving many right-hand sides of a small dense system: the Gram matrix takes 8*rows*rows bytes,
+// 320KB for 200 rows but 800MB for 10_000, which is why it is only kept below the threshold. Its rows*rows*cols
+// cost is paid by NewSolver alone. As with WithIncrementalResidual, it speeds up the solves that record their
+// errors, about ten times on 200x200 systems as measured by BenchmarkGramCache, and slightly slows down the others.
+// Larger systems are solved as if the option wasn't given.
+// RandomizedKaczmarz builds a new Solver for every call, so there it only behaves like WithIncrementalResidual.
+func WithGramCache(maxRows int) Option {
+	return func(o *options) {
+		o.gramRows = maxRows
+	}
+}
+
+// WithInputCheck turns the scan of the matrices and vectors of the system for NaNs and infinities on or off.
+//
+// The scan is on by default: a solver given a non-finite entry returns an ErrNonFiniteInput naming the first one
+// instead of iterating on garbage. It reads every entry once, which costs about as much as one full residual;
+// callers that already validated their data can turn it off to save that time.
+func WithInputCheck(enabled bool) Option {
+	return func(o *options) {
+		o.skipInputCheck = !enabled
+	}
+}
+
+// WithRankCheck makes RandomizedKaczmarz and RandomizedExtendedKaczmarz check whether A looks rank deficient
+// before iterating and store the answer into deficient.
+//
+// A rank deficient system has many solutions and the solvers return the one closest to the starting point, the
+// minimum norm solution when starting from zero, which can be surprising. A is deemed rank deficient if it has
+// more columns than rows or its condition number, estimated by EstimateConditionNumber with 30 steps, is at least
+// 1e8, past which the smallest singular value is lost in rounding errors. The check is logged as a warning to the
+// logger given to WithVerbose, if any. It costs about as much as forming the smaller Gram matrix of A once, which
+// a Solver only does for its first solve.
+func WithRankCheck(deficient *bool) Option {
+	return func(o *options) {
+		o.rankDeficient = deficient
+	}
+}
+
+// WithVerbose makes the solver count how many times every row is drawn and log the counts to logger at the end
+// of the solve, together with warnings about rows that are drawn disproportionately often or never.
+//
+// The rows are drawn from an alias table, so there are no rejected draws to report. The default is silent.
+func WithVerbose(logger *log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithTimeout gives the solver a time budget of d, counted from the start of the solve.
+//
+// The elapsed time is checked every few iterations (see WithCheckInterval). Once the budget is exceeded the solver
+// stops and returns the solution and errors computed so far, without an error. The budget composes with the
+// tolerance and the iteration count: whichever is reached first stops the solve. The number of iterations
+// completed is the length of the errors, or the last iteration of the history given to WithHistory.
+// A d of zero or less means no budget, the default.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// SamplingStrategy chooses the order in which a solver visits the rows, see WithSamplingStrategy
+type SamplingStrategy int
+
+const (
+	// IIDByNorm draws every row independently with probability proportional to its squared norm, the default.
+	// The same row can be drawn many times in a row.
+	IIDByNorm SamplingStrategy = iota
+	// Cyclic steps through the rows in order and starts over after the last one, like the original Kaczmarz method
+	Cyclic
+	// RandomPermutation visits every row once per epoch in a random order that is shuffled again for every epoch.
+	// Sampling without replacement often converges faster than IIDByNorm.
+	RandomPermutation
+)
+
+// WithSamplingStrategy chooses the order in which the solver visits the rows.
+//
+// The strategy applies to every sampler of the solver: the columns of RandomizedExtendedKaczmarz and
+// RandomizedGaussSeidel and the blocks of BlockRandomizedKaczmarz are visited the same way.
+// Cyclic and RandomPermutation visit every row with a positive probability exactly once per epoch, whatever its
+// probability, and never visit zero rows. GreedyRandomizedKaczmarz ignores this option.
+func WithSamplingStrategy(strategy SamplingStrategy) Option {
+	return func(o *options) {
+		o.strategy = strategy
+	}
+}
+
+// WithRowSampler makes RandomizedKaczmarz choose its rows with sampler instead of drawing them itself.
+//
+// It separates the sampling policy from the projections, so custom schemes can be tried without changing the
+// solver; NewNormSampler, NewUniformSampler, NewGreedySampler and NewAliasSampler build the usual ones. The
+// sampler takes precedence over WithSamplingStrategy and WithAdaptiveSampling, and the probabilities given by
+// WithRowProbabilities or WithRowScaling are ignored, although WithVerbose still counts the draws. The sampler is
+// Reset at the start of every solve and an IterateSampler observes x before every draw. A sampler holds state,
+// so concurrent solves need samplers of their own, given to Solver.Solve rather than NewSolver. The other solvers
+// ignore this option.
+func WithRowSampler(sampler RowSampler) Option {
+	return func(o *options) {
+		o.rowSampler = sampler
+	}
+}
+
+// WithAdaptiveSampling makes RandomizedKaczmarz recompute the row probabilities from the residual every
+// `every` iterations.
+//
+// Row i is then sampled with probability proportional to its squared residual (b_i - A_i*x)^2, so the equations
+// that are already satisfied are rarely chosen while the sampling stays random, unlike GreedyRandomizedKaczmarz.
+// Every update costs a full product A*x, so every should be at least about the number of rows.
+// The first iterations use the usual probabilities. Values of every below 1 turn the adaptive sampling off,
+// the default. The sampling strategy is ignored while adapting and the other solvers ignore this option.
+func WithAdaptiveSampling(every int) Option {
+	return func(o *options) {
+		o.adaptiveEvery = every
+	}
+}
+
+// WithFiniteCheck makes the solver scan its solution for NaNs and infinities every `every` iterations.
+//
+// Once one is found the solver stops and returns the solution and errors computed so far together with an
+// ErrNotFinite naming the entry and the iteration, instead of iterating on garbage. The scan costs a pass over
+// the solution, so it is off by default; values of every below 1 keep it off.
+// RandomizedKaczmarzMulti and RandomizedKaczmarzCmplx ignore this option.
+func WithFiniteCheck(every int) Option {
+	return func(o *options) {
+		o.finiteEvery = every
+	}
+}
+
+// WithEpochs sets the iterations budget in epochs, passes over the system, instead of the iterations passed
+// to the solver.
+//
+// An epoch is as many iterations as it takes to project on every row once on average: one iteration per row
+// for most solvers, per column for RandomizedGaussSeidel, per block for BlockRandomizedKaczmarz and per
+// samplesPerStep rows for AveragedKaczmarz. The budget is int(epochs*rows) iterations, so 50 epochs means the
+// same amount of work whatever the size of the system, and the errors of systems of different sizes can be
+// compared by dividing their index by the rows. A non-positive number of epochs keeps the iterations.
+func WithEpochs(epochs float64) Option {
+	return func(o *options) {
+		o.epochs = epochs
+	}
+}
+
+// WithEpochCheckpoints makes the solver compute and record the error once per epoch, after every rows-th
+// iteration, instead of after every iteration.
+//
+// Errors recorded at every iteration are expensive on huge systems, since each costs a full residual, and noisy,
+// since a single projection can increase the residual. With checkpoints the error i is the error after epoch i+1,
+// so a run of n iterations returns n/rows errors and WithHistory holds the iteration of each checkpoint. The epochs
+// are those of WithEpochs. The tolerance and the stagnation window are only checked at the checkpoints, although
+// WithProgress still reports on its own schedule.
+func WithEpochCheckpoints(enabled bool) Option {
+	return func(o *options) {
+		o.checkpoints = enabled
+	}
+}
+
+// WithReturnBestIterate makes RandomizedKaczmarz return the iterate with the lowest recorded error instead of
+// the last one.
+//
+// The residual of randomized Kaczmarz fluctuates from one iteration to the next, and on noisy problems the
+// iteration is semi-convergent: it gets closest to the solution before drifting away as it fits the noise. A copy
+// of x is kept whenever an error lower than all the previous ones is recorded, which needs the errors to be
+// recorded with keepErrors or WithHistory, and WithErrorMetric chooses what lowest means. If no error was
+// recorded the last iterate is returned. Solver.Continue goes on from the last iterate, not from the best one.
+// The other solvers ignore this option.
+func WithReturnBestIterate(enabled bool) Option {
+	return func(o *options) {
+		o.returnBest = enabled
+	}
+}
+
+// WithStopChannel makes the solver stop once stop is closed, for callers that don't use a context.
+//
+// Like the time budget of WithTimeout, the channel is only polled every few iterations, see WithCheckInterval,
+// and without blocking. The solver then returns the current solution, the errors so far and no error, and
+// WithStopReason reports StopCancelled. The channel can be closed from any goroutine.
+func WithStopChannel(stop <-chan struct{}) Option {
+	return func(o *options) {
+		o.stop = stop
+	}
+}
+
+// WithStagnation makes the solver stop once the relative decrease (e_{k-window}-e_k)/e_{k-window} of the error
+// over the last window iterations falls below threshold.
+//
+// Unlike the tolerance, the threshold doesn't depend on the scale of the problem: a threshold of 1e-3 with a window
+// of 1000 stops once a thousand iterations improve the error by less than 0.1%. Checking the decrease computes the
+// error after every iteration, even if the solver was not asked to keep the errors. A window below 1 turns the
+// check off, the default.
+func WithStagnation(window int, threshold float64) Option {
+	return func(o *options) {
+		o.window = window
+		o.stagnation = threshold
+	}
+}
+
+// WithStopReason makes the solver store why it stopped into reason
+func WithStopReason(reason *StopReason) Option {
+	return func(o *options) {
+		o.stopReason = reason
+	}
+}
+
+// WithResidual makes the solver store the residual b-A*x of the solution it returns into residual, which is
+// resized to the rows of A.
+//
+// Unlike the errors, which only measure the norm of the residual, it tells which equations are still poorly
+// satisfied, such as outliers or equations inconsistent with the others. Computing it costs one more matrix-vector
+// product at the end of the solve. RkRk and RkRek store y-U*V*b. RandomizedKaczmarzCmplx, RandomizedKaczmarzMulti
+// and StreamingKaczmarz ignore this option.
+func WithResidual(residual *mat.VecDense) Option {
+	return func(o *options) {
+		o.finalResidual = residual
+	}
+}
+
+// WithErrorMetric chooses what the errors returned by the solver, and the tolerance they are checked against, measure.
+//
+// ErrorMetricSolutionGap also needs WithTrueSolution. RandomizedKaczmarzMulti only supports ErrorMetricResidual.
+func WithErrorMetric(metric ErrorMetric) Option {
+	return func(o *options) {
+		o.metric = metric
+	}
+}
+
+// WithTrueSolution gives the solver the true solution of the system, used by ErrorMetricSolutionGap.
+//
+// solution must have as many rows as the vector returned by the solver.
+func WithTrueSolution(solution *mat.VecDense) Option {
+	return func(o *options) {
+		o.solution = solution
+	}
+}
+
+// newOptions applies opts over the default settings
+func newOptions(opts []Option) *options {
+	o := &options{
+		checkInterval: 100,
+		relaxation:    1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// source returns the random source a solve should draw from, or nil for the global source.
+//
+// A new source is created on every call so that concurrent solves never share state.
+func (o *options) source() rand.Source {
+	if !o.seeded {
+		return nil
+	}
+
+	return rand.NewSource(o.seed)
+}
+
+// startingPoint returns the vector of length n a solve starts from, a copy of guess or the zero vector if guess is nil.
+//
+// An ErrDimensionMismatch is returned if guess doesn't have n rows.
+func startingPoint(guess *mat.VecDense, guessName string, n int, matrixName string) (*mat.VecDense, error) {
+	x := mat.NewVecDense(n, nil)
+	if guess == nil {
+		return x, nil
+	}
+
+	if err := checkLength(guess, guessName, n, matrixName); err != nil {
+		return nil, err
+	}
+	x.CopyVec(guess)
+
+	return x, nil
+}
+
+// rowSampling replaces the norm-based probabilities of the rows of A with those given to WithRowProbabilities
+// or implied by WithRowScaling, if any.
+func (o *options) rowSampling(probVector, normsVector []float64) error {
+	switch {
+	case o.rowProbs != nil && o.rowScaling != nil:
+		return fmt.Errorf("%w: WithRowProbabilities and WithRowScaling can't be combined", ErrInvalidOption)
+	case o.normalize && (o.rowProbs != nil || o.rowScaling != nil):
+		return fmt.Errorf("%w: WithRowNormalization can't be combined with WithRowProbabilities or WithRowScaling", ErrInvalidOption)
+	case o.normalize:
+		// Every non-zero row scaled to unit norm has the same weight
+		uniform := make([]float64, len(normsVector))
+		for row := range uniform {
+			uniform[row] = 1
+		}
+		return applyRowProbabilities(probVector, normsVector, uniform)
+	case o.rowProbs != nil:
+		return applyRowProbabilities(probVector, normsVector, o.rowProbs)
+	case o.rowScaling != nil:
+		if err := checkScaling(o.rowScaling, len(normsVector)); err != nil {
+			return err
+		}
+
+		// The weights d_i^2*||a_i||^2 are squared relative to the largest so that they don't overflow
+		scaled := make([]float64, len(normsVector))
+		largest := 0.0
+		for row, d := range o.rowScaling {
+			scaled[row] = math.Abs(d) * normsVector[row]
+			largest = math.Max(largest, scaled[row])
+		}
+		for row := range scaled {
+			if largest > 0 {
+				ratio := scaled[row] / largest
+				scaled[row] = ratio * ratio
+			}
+		}
+		return applyRowProbabilities(probVector, normsVector, scaled)
+	}
+
+	return nil
+}
+
+// epochIterations returns the iterations of the epochs given to WithEpochs for a solver projecting on units
+// rows, columns or blocks per epoch, or iterations if no epochs were given. It also records the size of an epoch
+// for WithEpochCheckpoints.
+func (o *options) epochIterations(iterations, units int) int {
+	o.epochSize = units
+	if o.epochs > 0 {
+		return int(o.epochs * float64(units))
+	}
+
+	return iterations
+}
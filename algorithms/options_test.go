@@ -0,0 +1,331 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+	"time"
+)
+
+// illConditionedSystem returns a consistent system whose rows are all close to the same direction, on which plain
+// Kaczmarz crawls, and its solution
+func illConditionedSystem() (*mat.Dense, *mat.VecDense, *mat.VecDense) {
+	const rows, cols = 60, 6
+	rnd := rand.New(rand.NewSource(7))
+	A := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			A.Set(i, j, 1+0.05*rnd.NormFloat64())
+		}
+	}
+	want := mat.NewVecDense(cols, []float64{1, -1, 2, 0, 1, -2})
+	b := mat.NewVecDense(rows, nil)
+	b.MulVec(A, want)
+
+	return A, b, want
+}
+
+func TestMomentum(t *testing.T) {
+	A, b, want := illConditionedSystem()
+
+	x, plain, err := RandomizedKaczmarz(A, b, 1_000_000, 1e-16, true, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "plain", x.RawVector().Data, want.RawVector().Data)
+
+	x, heavy, err := RandomizedKaczmarz(A, b, 1_000_000, 1e-16, true, WithSeed(1), WithMomentum(0.5))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "momentum", x.RawVector().Data, want.RawVector().Data)
+
+	if len(heavy) >= len(plain) {
+		t.Errorf("%d iterations to the tolerance with momentum, %d without", len(heavy), len(plain))
+	}
+}
+
+func TestNonnegativity(t *testing.T) {
+	// The unconstrained solution [1 -1 2] has a negative entry, the nonnegative least-squares solution is [1 0 2]
+	A := mat.NewDense(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1})
+	b := mat.NewVecDense(3, []float64{1, -1, 2})
+
+	negative := false
+	x, _, err := RandomizedKaczmarz(A, b, 2000, 0, false, WithSeed(1), WithNonnegativity(true),
+		WithIterateSnapshot(1, func(iter int, x mat.Vector) {
+			for j := 0; j < x.Len(); j++ {
+				negative = negative || x.AtVec(j) < 0
+			}
+		}))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if negative {
+		t.Error("an iterate has a negative entry")
+	}
+	checkSolution(t, "x", x.RawVector().Data, []float64{1, 0, 2})
+
+	// A consistent system with a nonnegative solution on the boundary of the orthant
+	A, b, _ = smallSystem()
+	want := mat.NewVecDense(3, []float64{2, 0, 1})
+	b.MulVec(A, want)
+	x, _, err = RandomizedKaczmarz(A, b, 20_000, 0, false, WithSeed(1), WithNonnegativity(true),
+		WithInitialGuess(mat.NewVecDense(3, []float64{-5, -5, -5})))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for j, value := range x.RawVector().Data {
+		if value < 0 {
+			t.Errorf("x[%d] = %g", j, value)
+		}
+	}
+	checkSolution(t, "consistent", x.RawVector().Data, want.RawVector().Data)
+}
+
+// noisySystem returns an ill-conditioned square system with singular values from 1 down to 1e-3, a right-hand side
+// perturbed by noise, and the solution of the system without the noise, which only lies along the largest
+// singular values
+func noisySystem() (*mat.Dense, *mat.VecDense, *mat.VecDense) {
+	const n = 20
+	rnd := rand.New(rand.NewSource(3))
+	orthogonal := func() *mat.Dense {
+		M := mat.NewDense(n, n, nil)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				M.Set(i, j, rnd.NormFloat64())
+			}
+		}
+		var qr mat.QR
+		qr.Factorize(M)
+		Q := new(mat.Dense)
+		qr.QTo(Q)
+		return Q
+	}
+
+	values := make([]float64, n)
+	for k := range values {
+		values[k] = math.Pow(10, -3*float64(k)/(n-1))
+	}
+	U, V := orthogonal(), orthogonal()
+	A := new(mat.Dense)
+	A.Product(U, mat.NewDiagDense(n, values), V.T())
+
+	want := mat.NewVecDense(n, nil)
+	for k := 0; k < 3; k++ {
+		want.AddVec(want, V.ColView(k))
+	}
+	b := mat.NewVecDense(n, nil)
+	b.MulVec(A, want)
+	for i := 0; i < n; i++ {
+		b.SetVec(i, b.AtVec(i)+1e-2*rnd.NormFloat64())
+	}
+
+	return A, b, want
+}
+
+func TestTikhonov(t *testing.T) {
+	A, b, want := noisySystem()
+	gap := func(x *mat.VecDense) float64 {
+		diff := mat.NewVecDense(want.Len(), nil)
+		diff.SubVec(x, want)
+		return EuclideanNorm(diff)
+	}
+
+	// Left alone, plain Kaczmarz fits the noise amplified by the small singular values
+	plain, _, err := RandomizedKaczmarz(A, b, 200_000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ridge, _, err := RandomizedKaczmarz(A, b, 200_000, 0, false, WithSeed(1), WithTikhonov(1e-2))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if gap(&ridge) > gap(&plain)/4 {
+		t.Errorf("|x-x_true| = %g with lambda = 1e-2, %g without", gap(&ridge), gap(&plain))
+	}
+}
+
+func TestStopChannel(t *testing.T) {
+	A, b := inconsistentSystem()
+	stop := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+
+	// Without the channel the budget would take minutes, the inconsistent system never reaches the tolerance
+	var reason StopReason
+	start := time.Now()
+	x, errs, err := RandomizedKaczmarz(A, b, math.MaxInt32, 1e-30, true, WithStopChannel(stop), WithStopReason(&reason))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the solve returned %v after the channel was closed", elapsed)
+	}
+	if reason != StopCancelled {
+		t.Errorf("stopped because of %v, want %v", reason, StopCancelled)
+	}
+	if len(errs) == 0 || len(errs) == math.MaxInt32 || x.Len() != 2 {
+		t.Errorf("%d iterations and a solution of length %d, want a partial solve", len(errs), x.Len())
+	}
+}
+
+func TestResidualOutlier(t *testing.T) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(4)), 30, 4)
+	b := mat.NewVecDense(30, mat.Col(nil, 0, B))
+
+	// Equation 17 is inconsistent with the others
+	const outlier = 17
+	b.SetVec(outlier, b.AtVec(outlier)+10)
+
+	residual := new(mat.VecDense)
+	if _, _, err := RandomizedExtendedKaczmarz(A, b, 50_000, 0, false, WithSeed(1), WithResidual(residual)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if residual.Len() != 30 {
+		t.Fatalf("the residual has %d rows, want 30", residual.Len())
+	}
+
+	largest := 0
+	for i := 0; i < residual.Len(); i++ {
+		if math.Abs(residual.AtVec(i)) > math.Abs(residual.AtVec(largest)) {
+			largest = i
+		}
+	}
+	if largest != outlier {
+		t.Errorf("row %d has the largest residual %g, want the outlier %d with %g",
+			largest, residual.AtVec(largest), outlier, residual.AtVec(outlier))
+	}
+}
+
+func TestRecentRows(t *testing.T) {
+	A, b, want := illConditionedSystem()
+
+	x, single, err := RandomizedKaczmarz(A, b, 1_000_000, 1e-16, true, WithSeed(1), WithRecentRows(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "k = 1", x.RawVector().Data, want.RawVector().Data)
+
+	x, window, err := RandomizedKaczmarz(A, b, 1_000_000, 1e-16, true, WithSeed(1), WithRecentRows(4))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "k = 4", x.RawVector().Data, want.RawVector().Data)
+
+	if len(window) >= len(single) {
+		t.Errorf("%d iterations to the tolerance with a window of 4 rows, %d with a single row", len(window), len(single))
+	}
+}
+
+func TestRowNormalization(t *testing.T) {
+	// Rows of very different norms, which are sampled very differently with and without the normalization
+	A, b, want := smallSystem()
+	for i, scale := range []float64{1, 10, 0.1, 3} {
+		row := A.RawRowView(i)
+		for j := range row {
+			row[j] *= scale
+		}
+		b.SetVec(i, b.AtVec(i)*scale)
+	}
+
+	plain, _, err := RandomizedKaczmarz(A, b, 20_000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	normalized, _, err := RandomizedKaczmarz(A, b, 20_000, 0, false, WithSeed(1), WithRowNormalization(true))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	checkSolution(t, "without normalization", plain.RawVector().Data, want)
+	checkSolution(t, "with normalization", normalized.RawVector().Data, want)
+	if !mat.EqualApprox(&plain, &normalized, 1e-8) {
+		t.Errorf("x = %v with normalization, %v without", normalized.RawVector().Data, plain.RawVector().Data)
+	}
+}
+
+func TestEpochCheckpoints(t *testing.T) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(5)), 30, 4)
+	b := mat.NewVecDense(30, mat.Col(nil, 0, B))
+	b.SetVec(0, b.AtVec(0)+1)
+
+	for _, iterations := range []int{3000, 3010, 29} {
+		var history ConvergenceHistory
+		_, errs, err := RandomizedKaczmarz(A, b, iterations, 0, true, WithSeed(1), WithEpochCheckpoints(true),
+			WithHistory(&history))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		want := iterations / 30
+		if len(errs) != want || len(history.Residuals) != want || len(history.Iterations) != want {
+			t.Errorf("%d iterations: %d errors and a history of %d, want %d", iterations, len(errs), len(history.Residuals), want)
+			continue
+		}
+		// The iterations are counted from 0, so the first epoch ends after iteration 29
+		for k, iteration := range history.Iterations {
+			if iteration != 30*(k+1)-1 {
+				t.Errorf("%d iterations: checkpoint %d recorded after iteration %d, want %d", iterations, k, iteration, 30*(k+1)-1)
+				break
+			}
+		}
+	}
+}
+
+func TestReturnBestIterate(t *testing.T) {
+	A, b := inconsistentSystem()
+	squaredResidual := func(x *mat.VecDense) float64 {
+		r := mat.NewVecDense(3, nil)
+		r.MulVec(A, x)
+		r.SubVec(b, r)
+		return EuclideanNormSquared(r)
+	}
+
+	// On an inconsistent system the residual keeps fluctuating, so the last iterate isn't the best one
+	last, errs, err := RandomizedKaczmarz(A, b, 50, 0, true, WithSeed(2))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	best, _, err := RandomizedKaczmarz(A, b, 50, 0, true, WithSeed(2), WithReturnBestIterate(true))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	lowest := errs[0]
+	for _, e := range errs {
+		lowest = math.Min(lowest, e)
+	}
+	if !(squaredResidual(&best) < squaredResidual(&last)) {
+		t.Errorf("the best iterate has a squared residual of %g, the last one %g", squaredResidual(&best), squaredResidual(&last))
+	}
+	if math.Abs(squaredResidual(&best)-lowest) > 1e-12 {
+		t.Errorf("the best iterate has a squared residual of %g, want the lowest error %g", squaredResidual(&best), lowest)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	A, b := inconsistentSystem()
+
+	var iters []int
+	var residuals []float64
+	_, errs, err := RandomizedKaczmarz(A, b, 100, 0, true, WithSeed(1), WithProgress(7, func(iter int, residual float64) {
+		iters = append(iters, iter)
+		residuals = append(residuals, residual)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(iters) != 100/7 {
+		t.Fatalf("the callback was called %d times, want %d", len(iters), 100/7)
+	}
+	for k, iter := range iters {
+		if iter != 7*(k+1) {
+			t.Errorf("call %d after %d iterations, want %d", k, iter, 7*(k+1))
+		}
+		if residuals[k] != errs[iter-1] {
+			t.Errorf("call %d reported %g, want the error %g", k, residuals[k], errs[iter-1])
+		}
+	}
+}
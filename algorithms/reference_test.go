@@ -0,0 +1,161 @@
+package algorithms
+
+import (
+	"github.com/alexandru-balan/go-rk-rk/sparse"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+// referenceKaczmarz is the randomized Kaczmarz algorithm written as plainly as possible, the oracle the solvers
+// are checked against.
+//
+// Everything runs serially on a []float64 with plain loops over A.At: no BLAS, no row views, no goroutines and
+// no scratch buffers. x is projected onto the rows in the order next returns them, so with the sampler a solver
+// builds for a seed it has to take the same steps as the solver, up to rounding errors.
+func referenceKaczmarz(A mat.Matrix, b *mat.VecDense, iterations int, next func() int) []float64 {
+	_, cols := A.Dims()
+	x := make([]float64, cols)
+
+	for k := 0; k < iterations; k++ {
+		i := next()
+
+		dot, squared := 0.0, 0.0
+		for j := 0; j < cols; j++ {
+			dot += A.At(i, j) * x[j]
+			squared += A.At(i, j) * A.At(i, j)
+		}
+
+		step := (b.AtVec(i) - dot) / squared
+		for j := 0; j < cols; j++ {
+			x[j] += step * A.At(i, j)
+		}
+	}
+
+	return x
+}
+
+// seededRows returns the rows of A drawn by the default sampler of a solve seeded with seed
+func seededRows(A mat.Matrix, seed uint64) func() int {
+	return NewNormSampler(A, rand.NewSource(seed)).Next
+}
+
+// cyclicRows returns the rows of a matrix without zero rows in the order of the Cyclic sampling strategy
+func cyclicRows(rows int) func() int {
+	i := -1
+	return func() int {
+		i = (i + 1) % rows
+		return i
+	}
+}
+
+// checkAgainstReference fails the test if x isn't the reference solution up to a relative tolerance
+func checkAgainstReference(t *testing.T, name string, x mat.Vector, reference []float64, tolerance float64) {
+	t.Helper()
+	for j, want := range reference {
+		if math.Abs(x.AtVec(j)-want) > tolerance*math.Max(1, math.Abs(want)) {
+			t.Errorf("%s: x[%d] = %.17g, the reference gives %.17g", name, j, x.AtVec(j), want)
+			return
+		}
+	}
+}
+
+func TestAgainstReference(t *testing.T) {
+	// An inconsistent system, so that x keeps moving for all the iterations and every step is compared
+	A, B, _ := randomSystem(rand.New(rand.NewSource(5)), 30, 6)
+	b := mat.NewVecDense(30, nil)
+	b.CopyVec(B.ColView(0))
+	b.SetVec(0, b.AtVec(0)+1)
+
+	const iterations = 500
+	reference := referenceKaczmarz(A, b, iterations, seededRows(A, 11))
+
+	x, _, err := RandomizedKaczmarz(A, b, iterations, 0, false, WithSeed(11))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "RandomizedKaczmarz", &x, reference, 1e-12)
+
+	x, _, err = RandomizedKaczmarz(A, b, iterations, 0, true, WithSeed(11), WithIncrementalResidual(true))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "WithIncrementalResidual", &x, reference, 1e-10)
+
+	solver, err := NewSolver(A, WithGramCache(100))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	x, _, err = solver.Solve(b, iterations, 0, true, WithSeed(11))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "Solver with WithGramCache", &x, reference, 1e-10)
+
+	x, _, err = AveragedKaczmarz(A, b, 1, iterations, 0, false, WithSeed(11))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "AveragedKaczmarz", &x, reference, 1e-12)
+
+	X, _, err := RandomizedKaczmarzMulti(A, mat.NewDense(30, 1, b.RawVector().Data), iterations, 0, false, WithSeed(11))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "RandomizedKaczmarzMulti", X.ColView(0), reference, 1e-12)
+}
+
+func TestSparseAgainstReference(t *testing.T) {
+	// A banded matrix, stored both densely and in CSR
+	const rows, cols = 20, 8
+	dense := mat.NewDense(rows, cols, nil)
+	indptr := []int{0}
+	var ind []int
+	var data []float64
+	for i := 0; i < rows; i++ {
+		for j := i % cols; j < cols && j <= i%cols+2; j++ {
+			value := float64(1 + (i+j)%5)
+			dense.Set(i, j, value)
+			ind, data = append(ind, j), append(data, value)
+		}
+		indptr = append(indptr, len(ind))
+	}
+	A := sparse.NewCSR(rows, cols, indptr, ind, data)
+
+	b := mat.NewVecDense(rows, nil)
+	for i := 0; i < rows; i++ {
+		b.SetVec(i, float64(i%3)-1)
+	}
+
+	const iterations = 300
+	reference := referenceKaczmarz(dense, b, iterations, cyclicRows(rows))
+
+	x, _, err := RandomizedKaczmarzSparse(A, b, iterations, 0, false, WithSamplingStrategy(Cyclic))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "RandomizedKaczmarzSparse", &x, reference, 1e-12)
+
+	x, _, err = RandomizedKaczmarz(dense, b, iterations, 0, false, WithSamplingStrategy(Cyclic))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "RandomizedKaczmarz", &x, reference, 1e-12)
+
+	// The float32 solver only sees the rounded matrix, exact here since every entry is a small integer
+	A32 := blas32.General{Rows: rows, Cols: cols, Stride: cols, Data: make([]float32, rows*cols)}
+	b32 := make([]float32, rows)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			A32.Data[i*cols+j] = float32(dense.At(i, j))
+		}
+		b32[i] = float32(b.AtVec(i))
+	}
+	x, _, err = RandomizedKaczmarzF32(A32, b32, iterations, 0, false, WithSamplingStrategy(Cyclic))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkAgainstReference(t, "RandomizedKaczmarzF32", &x, reference, 1e-12)
+}
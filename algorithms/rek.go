@@ -0,0 +1,136 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"sync"
+)
+
+// RandomizedExtendedKaczmarz returns the least-squares solution of a possibly inconsistent system A*x=b
+// using the randomized extended Kaczmarz algorithm of Zouzias and Freris.
+//
+// Plain randomized Kaczmarz keeps oscillating around the least-squares solution when the system is inconsistent.
+// This algorithm also maintains a vector z, projected against random columns of A, that converges to the part
+// of b outside the range of A. The rows of A are then projected against b-z, which is consistent.
+//
+// Parameters:
+// A is a mat.Matrix representing the system. mat.Dense rows are read without copying.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the vector x that is the least-squares solution of A*x=b and a []float64 array containing the
+// errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// For an inconsistent system the squared residual can't drop below that of the least-squares solution,
+// so the tolerance must be set above it for the early exit to trigger.
+func RandomizedExtendedKaczmarz(A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if o.rankDeficient != nil {
+		deficient, cond := estimateRank(A)
+		o.reportRank(deficient, cond, "A")
+	}
+
+	Atr := mat.NewDense(colsA, rowsA, nil)
+	Atr.Copy(A.T())
+	readerAtr := newRowReader(Atr)
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	z := mat.NewVecDense(rowsA, nil)
+	z.CopyVec(b)
+
+	// Reader of the rows of A, which reuses the same vector for every row
+	readerA := newRowReader(A)
+
+	track, err := newTracker(o, tolerance, keepErrors, x, residualOf(A, x, b))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each row and each column of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+	probsAtr := make([]float64, colsA)
+	normsAtr := make([]float64, colsA)
+
+	var frobeniusA, frobeniusAtr float64
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(2)
+	go GetRowsProbability(probsA, normsA, &frobeniusA, A, rowsA, &waitGroup)
+	go GetRowsProbability(probsAtr, normsAtr, &frobeniusAtr, Atr, colsA, &waitGroup)
+	waitGroup.Wait()
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	samplerAtr, err := o.newSampler("column", "A", probsAtr, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
+	// Removing a random column direction from z and projecting x onto a random row of A*x=b-z
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+		randAtr := samplerAtr.Next()
+
+		chosenA := readerA.at(randA)
+		chosenAtr := readerAtr.at(randAtr)
+
+		z.AddScaledVec(
+			z,
+			-dotOver(chosenAtr, z, normsAtr[randAtr])/normsAtr[randAtr],
+			chosenAtr)
+
+		x.AddScaledVec(
+			x,
+			o.relaxation*((b.AtVec(randA)-z.AtVec(randA)-mat.Dot(chosenA, x))/normsA[randA])/normsA[randA],
+			chosenA)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
@@ -0,0 +1,59 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// ResidualNorm returns the euclidean norm ||b-A*x|| of the residual of x for the system A*x=b.
+//
+// It checks the output of any single-system solver independently of the errors the solver tracked.
+// Note that the errors returned by the solvers are squared residuals, while this is the plain norm.
+// Like the mat package, it panics if the dimensions of A, x and b don't agree.
+func ResidualNorm(A mat.Matrix, x, b *mat.VecDense) float64 {
+	return math.Sqrt(residualOf(A, x, b)())
+}
+
+// CoupledResidualNorm returns the euclidean norm ||y-U*V*b|| of the residual of b for the system U*V*b=y
+// solved by RkRk and RkRek.
+//
+// The product U*V is never formed, so this costs two matrix-vector products.
+// Like the mat package, it panics if the dimensions of U, V, b and y don't agree.
+func CoupledResidualNorm(U, V mat.Matrix, b, y *mat.VecDense) float64 {
+	return math.Sqrt(coupledResidualOf(U, V, b, y)())
+}
+
+// reportResidual stores the residual b-A*x into the vector given to WithResidual, if any
+func (o *options) reportResidual(A mat.Matrix, x, b *mat.VecDense) {
+	if o.finalResidual == nil {
+		return
+	}
+
+	o.finalResidual.Reset()
+	sparseRows, isSparse := A.(rowNonZeroer)
+	if !isSparse {
+		o.finalResidual.MulVec(A, x)
+		o.finalResidual.SubVec(b, o.finalResidual)
+		return
+	}
+
+	rows, _ := A.Dims()
+	o.finalResidual.ReuseAsVec(rows)
+	for i := 0; i < rows; i++ {
+		ind, data := sparseRows.RowNonZeros(i)
+		o.finalResidual.SetVec(i, b.AtVec(i)-sparseDot(ind, data, x.RawVector().Data))
+	}
+}
+
+// reportCoupledResidual stores the residual y-U*V*b into the vector given to WithResidual, if any
+func (o *options) reportCoupledResidual(U, V mat.Matrix, b, y *mat.VecDense) {
+	if o.finalResidual == nil {
+		return
+	}
+
+	x := new(mat.VecDense)
+	x.MulVec(V, b)
+	o.finalResidual.Reset()
+	o.finalResidual.MulVec(U, x)
+	o.finalResidual.SubVec(y, o.finalResidual)
+}
@@ -0,0 +1,111 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"time"
+)
+
+// Result is the outcome of a solve in plain types, ready to be marshalled to JSON by a service
+type Result struct {
+	// Solution holds the entries of the solution x
+	Solution []float64 `json:"solution"`
+	// Iterations is the number of iterations performed
+	Iterations int `json:"iterations"`
+	// FinalResidual is the euclidean norm ||b-A*x|| of the residual of the solution, see ResidualNorm
+	FinalResidual float64 `json:"final_residual"`
+	// Converged is set if the solve stopped because the error dropped to the tolerance
+	Converged bool `json:"converged"`
+	// StopReason tells why the solve stopped, as returned by StopReason.String
+	StopReason string `json:"stop_reason"`
+	// RankDeficient is set if WithRankCheck found A rank deficient, in which case Solution is only one of many
+	RankDeficient bool `json:"rank_deficient,omitempty"`
+}
+
+// NewResult builds the Result of the solution x of A*x=b found after iterations iterations, stopped for reason.
+//
+// The iterations are the length of the errors returned by a solver that kept them, and reason is the one given by
+// WithStopReason. The residual is computed from scratch, so it doesn't depend on the error metric of the solve.
+// encoding/json can't marshal a NaN or an infinity, which a solution may hold if the iteration diverged.
+// Like the mat package, it panics if the dimensions of A, x and b don't agree.
+func NewResult(A mat.Matrix, x, b *mat.VecDense, iterations int, reason StopReason) Result {
+	solution := make([]float64, x.Len())
+	for j := range solution {
+		solution[j] = x.AtVec(j)
+	}
+
+	return Result{
+		Solution:      solution,
+		Iterations:    iterations,
+		FinalResidual: ResidualNorm(A, x, b),
+		Converged:     reason == StopTolerance,
+		StopReason:    reason.String(),
+	}
+}
+
+// Result returns the Result of the solve so far, see NewResult, for the iterate Solve would return. RankDeficient
+// is set if the solve was started WithRankCheck and A looks rank deficient.
+func (state *SolveState) Result() Result {
+	result := NewResult(state.solver.matrix, state.track.solution(), state.b, state.done, state.track.reason)
+	result.RankDeficient = state.rankDeficient
+
+	return result
+}
+
+// Report is the outcome of SolveWithReport, filled in whether the solve converged, ran out of budget or failed
+type Report struct {
+	Result
+	// Elapsed is the time the solve took, setup included
+	Elapsed time.Duration `json:"elapsed"`
+	// Err is the error the solve stopped with, e.g. context.DeadlineExceeded, or nil
+	Err error `json:"-"`
+}
+
+// SolveWithReport solves A*x=b with RandomizedKaczmarz and reports how far it got, for schedulers that retry
+// with a larger budget when a solve doesn't converge.
+//
+// Parameters:
+// ctx bounds the solve: once it is done the solve stops, as RandomizedKaczmarzCtx does.
+// A, b, iterations, tolerance and opts are those of RandomizedKaczmarz.
+//
+// Returns a Report that is always filled in and never a panic, even for nil inputs such as a (*mat.Dense)(nil)
+// given as A. A solve stopped by the deadline or the cancellation of ctx reports the iterations it ran, the
+// residual of its current solution and ctx.Err() as Err. Its StopReason tells a missed deadline from a
+// cancellation: "timeout" if the deadline of ctx passed, "cancelled" if ctx was cancelled. If the solve can't
+// start, because the inputs or the options are invalid, only Err and Elapsed are set.
+//
+// Notes:
+// Converged needs the tolerance to be checked, so the residual is computed after every iteration when the
+// tolerance is positive; WithEpochCheckpoints or WithIncrementalResidual make that cheaper. With a tolerance of 0
+// the solve runs all its iterations and reports the residual it reached.
+func SolveWithReport(ctx context.Context, A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, opts ...Option) Report {
+	start := time.Now()
+	report := func(result Result, err error) Report {
+		return Report{Result: result, Elapsed: time.Since(start), Err: err}
+	}
+
+	if isNil(A) || b == nil {
+		return report(Result{}, fmt.Errorf("%w: A and b must not be nil", ErrDimensionMismatch))
+	}
+	rowsA, _ := A.Dims()
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return report(Result{}, err)
+	}
+
+	solver, err := NewSolver(A, opts...)
+	if err != nil {
+		return report(Result{}, err)
+	}
+	state, err := solver.Start(b, tolerance, tolerance > 0)
+	if err != nil {
+		return report(Result{}, err)
+	}
+
+	if iterations < 0 {
+		iterations = 100_000
+	}
+	err = solver.run(ctx, state, state.o.epochIterations(iterations, rowsA))
+
+	return report(state.Result(), err)
+}
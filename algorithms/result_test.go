@@ -0,0 +1,114 @@
+package algorithms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// inconsistentSystem returns a system whose residual never drops to zero, so a solve always runs all its iterations
+func inconsistentSystem() (*mat.Dense, *mat.VecDense) {
+	A := mat.NewDense(3, 2, []float64{1, 0, 0, 1, 1, 1})
+	b := mat.NewVecDense(3, []float64{1, 1, 3})
+
+	return A, b
+}
+
+func TestSolveWithReportConverged(t *testing.T) {
+	A := mat.NewDense(3, 2, []float64{1, 0, 0, 1, 1, 1})
+	b := mat.NewVecDense(3, []float64{1, 2, 3})
+
+	report := SolveWithReport(context.Background(), A, b, 10_000, 1e-20, WithSeed(1))
+	if report.Err != nil {
+		t.Fatalf("unexpected error %v", report.Err)
+	}
+	if !report.Converged || report.StopReason != StopTolerance.String() {
+		t.Errorf("converged = %v, stop reason = %q, want a converged solve", report.Converged, report.StopReason)
+	}
+	if report.Iterations == 0 || report.Iterations >= 10_000 {
+		t.Errorf("iterations = %d, want a solve stopped early", report.Iterations)
+	}
+	checkSolution(t, "solution", report.Solution, []float64{1, 2})
+}
+
+func TestSolveWithReportTimedOut(t *testing.T) {
+	A, b := inconsistentSystem()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	report := SolveWithReport(ctx, A, b, 1<<40, 0, WithSeed(1))
+	if !errors.Is(report.Err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", report.Err, context.DeadlineExceeded)
+	}
+	if report.Converged || report.StopReason != StopTimeout.String() {
+		t.Errorf("converged = %v, stop reason = %q, want a timed out solve", report.Converged, report.StopReason)
+	}
+	if report.Iterations == 0 || len(report.Solution) != 2 || report.FinalResidual == 0 {
+		t.Errorf("report = %+v, want the partial progress of the solve", report.Result)
+	}
+	if report.Elapsed < 20*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least the deadline", report.Elapsed)
+	}
+}
+
+func TestSolveWithReportCancelled(t *testing.T) {
+	A, b := inconsistentSystem()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := SolveWithReport(ctx, A, b, 1000, 0, WithSeed(1))
+	if !errors.Is(report.Err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", report.Err, context.Canceled)
+	}
+	if report.StopReason != StopCancelled.String() || report.Iterations != 0 || len(report.Solution) != 2 {
+		t.Errorf("report = %+v, want a solve cancelled before its first iteration", report.Result)
+	}
+}
+
+func TestSolveWithReportNil(t *testing.T) {
+	_, b := inconsistentSystem()
+
+	inputs := []struct {
+		name string
+		A    mat.Matrix
+		b    *mat.VecDense
+	}{
+		{"nil A", nil, b},
+		{"typed nil A", (*mat.Dense)(nil), b},
+		{"nil b", mat.NewDense(3, 2, nil), nil},
+	}
+
+	for _, input := range inputs {
+		report := SolveWithReport(context.Background(), input.A, input.b, 10, 0)
+		if !errors.Is(report.Err, ErrDimensionMismatch) {
+			t.Errorf("%s: got error %v, want %v", input.name, report.Err, ErrDimensionMismatch)
+		}
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	A := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	x := mat.NewVecDense(2, []float64{1, 2})
+	b := mat.NewVecDense(2, []float64{1, 3})
+
+	result := NewResult(A, x, b, 42, StopTolerance)
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(decoded, result) {
+		t.Errorf("decoded %+v, want %+v", decoded, result)
+	}
+	if decoded.FinalResidual != 1 || !decoded.Converged || decoded.StopReason != StopTolerance.String() {
+		t.Errorf("decoded %+v, want a residual of 1 and a converged solve", decoded)
+	}
+}
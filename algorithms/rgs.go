@@ -0,0 +1,110 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// RandomizedGaussSeidel returns the least-squares solution of the system A*x=b using the randomized
+// Gauss-Seidel (coordinate descent) algorithm of Leventhal and Lewis.
+//
+// It is the column counterpart of RandomizedKaczmarz: at every iteration a column of A is chosen with probability
+// proportional to its squared norm and the matching entry of x is moved to minimize the residual along that column.
+//
+// Parameters:
+// A is a mat.Matrix representing the system.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed or WithRelaxation.
+//
+// Returns the vector x that is the least-squares solution of A*x=b and a []float64 array containing the
+// errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// Unlike RandomizedKaczmarz this algorithm also converges on inconsistent systems, but when A has more columns
+// than rows the solution it converges to depends on the starting point and is in general not the minimum norm one.
+// The residual b-A*x is updated with the chosen column at every iteration, so no iteration needs a full
+// matrix product.
+func RandomizedGaussSeidel(A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, colsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// The columns of A are the rows of its transpose
+	Atr := mat.NewDense(colsA, rowsA, nil)
+	Atr.Copy(A.T())
+	readerAtr := newRowReader(Atr)
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	residual := mat.NewVecDense(rowsA, nil)
+	residual.MulVec(A, x)
+	residual.SubVec(b, residual)
+
+	track, err := newTracker(o, tolerance, keepErrors, x, func() float64 {
+		return EuclideanNormSquared(residual)
+	})
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each column of A
+	probsAtr := make([]float64, colsA)
+	normsAtr := make([]float64, colsA)
+
+	frobeniusA := rowsProbability(probsAtr, normsAtr, Atr)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerAtr, err := o.newSampler("column", "A", probsAtr, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
+	// Moving a random entry of x along its column and updating the residual to match
+	for i := 0; i < iterations; i++ {
+		randAtr := samplerAtr.Next()
+		chosenAtr := readerAtr.at(randAtr)
+
+		step := o.relaxation * dotOver(chosenAtr, residual, normsAtr[randAtr]) / normsAtr[randAtr]
+
+		x.SetVec(randAtr, x.AtVec(randAtr)+step)
+		residual.AddScaledVec(residual, -step, chosenAtr)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
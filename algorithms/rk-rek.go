@@ -8,99 +8,156 @@ import (
 // RkRek returns the min-norm least-squares solution of a system A*b=y
 //
 // Parameters:
-// U and V are two matrices such that U*V=A.
+// U and V are two matrices such that U*V=A. Any mat.Matrix is accepted, mat.Dense rows are read without copying.
 // y is a vector such that A*b=y.
 // iterations is the maximum number of iterations the algorithm is allowed to perform.
 // tolerance is the desired error.
-// keepErrors is an optional boolean specifying whether to keep the errors calculated at each step.
+// keepErrors is a boolean specifying whether to keep the errors calculated at each step.
+// opts are optional settings such as WithSeed or WithRelaxation.
 //
 // Returns the b vector which is the solution to A*b=y and an array of errors.
+// An ErrDimensionMismatch is returned if y doesn't have as many rows as U or V doesn't have a row for every column
+// of U, and an ErrZeroMatrix if U or V is zero.
 //
 // Notes:
-// Pass a negative number as the iterations number to default to 100_000.
-// Only the first value in keepErrors is evaluated.
-// If keepErrors[0] is false then the returned errors array will be empty.
-func RkRek(U, V *mat.Dense, y *mat.VecDense, iterations int, tolerance float64, keepErrors ...bool) (mat.VecDense, []float64) {
+// The two systems are coupled through the intermediate x of U*x=y. Every iteration takes a randomized extended
+// Kaczmarz step on U*x=y, then projects b onto a random row r of V*b=x, whose right-hand side is the current
+// entry x_r. x has one entry per column of U and r is a row of V, which is why V must have exactly one row per
+// column of U.
+// Pass a negative number as the iterations number to default to 100_000, or 0 to get the starting point back,
+// as in RandomizedKaczmarz.
+// The i-th entry of the errors array is the squared residual after iteration i.
+// The algorithm stops as soon as the error drops to tolerance, so the length of the errors array is the
+// number of iterations actually performed.
+// If keepErrors is false then the returned errors array will be nil, and unless WithHistory is used the tolerance
+// is never checked.
+func RkRek(U, V mat.Matrix, y *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
 	// STEP 0.
 	// Initialization of variables
 	if iterations < 0 {
 		iterations = 100_000
 	}
 
+	o := newOptions(opts)
+	src := o.source()
+
 	rowsU, colsU := U.Dims()
 	rowsV, colsV := V.Dims()
+	iterations = o.epochIterations(iterations, rowsU)
+	if err := checkLength(y, "y", rowsU, "U"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := checkCoupling(U, V); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(U, "U"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(V, "V"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(y, "y"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
 
 	Utr := mat.NewDense(colsU, rowsU, nil)
 	Utr.Copy(U.T())
+	readerUtr := newRowReader(Utr)
 
-	x := mat.NewVecDense(colsU, nil)
+	x, err := startingPoint(o.intermediate, "the initial intermediate", colsU, "the columns of U")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
 	z := mat.NewVecDense(rowsU, nil)
 	z.CopyVec(y)
-	b := mat.NewVecDense(colsV, nil)
+	b, err := startingPoint(o.initialGuess, "the initial guess", colsV, "the columns of V")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
 
-	var errors []float64
+	// Readers of the rows of U and V, which reuse the same vector for every row
+	readerU := newRowReader(U)
+	readerV := newRowReader(V)
 
-	// STEP 1.
-	// Compute the squared frobenius norm of U, V and U transposed
-	frobeniusU := FrobeniusSquared(U)
-	frobeniusV := FrobeniusSquared(V)
-	frobeniusUtr := FrobeniusSquared(Utr)
+	track, err := newTracker(o, tolerance, keepErrors, b, coupledResidualOf(U, V, b, y))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
 
-	// STEP 2.
+	// STEP 1.
 	// Compute the probability of choosing a row from U, V and Utr(probability for each column of U)
+	// together with the norm of every row
 	probsU := make([]float64, rowsU)
+	normsU := make([]float64, rowsU)
 	probsV := make([]float64, rowsV)
+	normsV := make([]float64, rowsV)
 	probsUtr := make([]float64, colsU)
+	normsUtr := make([]float64, colsU)
 
+	var frobeniusU, frobeniusV, frobeniusUtr float64
 	waitGroup := new(sync.WaitGroup)
 	waitGroup.Add(3)
-	go GetRowsProbability(probsU, frobeniusU, U, rowsU, waitGroup)
-	go GetRowsProbability(probsV, frobeniusV, V, rowsV, waitGroup)
-	go GetRowsProbability(probsUtr, frobeniusUtr, Utr, colsU, waitGroup)
+	go GetRowsProbability(probsU, normsU, &frobeniusU, U, rowsU, waitGroup)
+	go GetRowsProbability(probsV, normsV, &frobeniusV, V, rowsV, waitGroup)
+	go GetRowsProbability(probsUtr, normsUtr, &frobeniusUtr, Utr, colsU, waitGroup)
 	waitGroup.Wait()
+	if err := checkNonZero(frobeniusU, "U"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := checkNonZero(frobeniusV, "V"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerU, err := o.newSampler("row", "U", probsU, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	samplerV, err := o.newSampler("row", "V", probsV, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	samplerUtr, err := o.newSampler("column", "U", probsUtr, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
 
-	// STEP 3.
+	// STEP 2.
 	// Main algorithm routine. Choosing random rows and updating z, x and b vectors
 	for i := 0; i < iterations; i++ {
-		randU := GetRandomRow(probsU)
-		randV := GetRandomRow(probsV)
-		randUtr := GetRandomRow(probsUtr)
+		randU := samplerU.Next()
+		randV := samplerV.Next()
+		randUtr := samplerUtr.Next()
 
-		chosenU := U.RowView(randU)
-		chosenV := V.RowView(randV)
-		chosenUtr := Utr.RowView(randUtr)
+		chosenU := readerU.at(randU)
+		chosenV := readerV.at(randV)
+		chosenUtr := readerUtr.at(randUtr)
 
-		euclideanU := EuclideanNormSquared(chosenU)
-		euclideanV := EuclideanNormSquared(chosenV)
-		euclideanUtr := EuclideanNormSquared(chosenUtr)
+		normU := normsU[randU]
+		normV := normsV[randV]
+		normUtr := normsUtr[randUtr]
 
 		z.AddScaledVec(
 			z,
-			-mat.Dot(chosenUtr, z)/euclideanUtr,
+			-dotOver(chosenUtr, z, normUtr)/normUtr,
 			chosenUtr)
 
 		x.AddScaledVec(
 			x,
-			(y.AtVec(randU)-z.AtVec(randU)-mat.Dot(chosenU, x))/euclideanU,
+			o.relaxation*((y.AtVec(randU)-z.AtVec(randU)-mat.Dot(chosenU, x))/normU)/normU,
 			chosenU)
 
 		b.AddScaledVec(
 			b,
-			(x.At(randV, 0)-mat.Dot(chosenV, b))/euclideanV,
+			o.relaxation*((x.At(randV, 0)-mat.Dot(chosenV, b))/normV)/normV,
 			chosenV)
 
-		if keepErrors[0] {
-			errVec := new(mat.VecDense)
-			errVec.MulVec(V, b)
-			errVec2 := new(mat.VecDense)
-			errVec2.MulVec(U, errVec)
-			errVec2.SubVec(errVec2, y)
-			errors = append(errors, EuclideanNormSquared(errVec2))
-			if errors[i] <= tolerance {
-				break
-			}
+		if track.record(i) {
+			break
 		}
 	}
 
-	return *b, errors
+	o.reportCoupledResidual(U, V, b, y)
+
+	return *b, track.errors, track.err
 }
@@ -9,18 +9,40 @@ import (
 // Knowing the whole matrix A is unnecessary and so it is never computed by the algorithm.
 //
 // Parameters:
-// U, V are mat.Dense matrices that are a defactorization of A.
+// U, V are mat.Matrix matrices that are a defactorization of A. mat.Dense rows are read without copying.
 // y is mat.VecDense vector that represents the expected output for the system.
 // iterations is an int representing how many times MAX is the algorithm allowed to run.
 // tolerance is a float64 that represents the maximal error allowed.
-// keepErrors is an optional boolean that specifies whether you want the function to retain the error at each iteration.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed or WithRelaxation.
 //
 // Returns the vector b that solves A*b=y and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if y doesn't have as many rows as U or V doesn't have a row for every column
+// of U, and an ErrZeroMatrix if U or V is zero.
 //
 // Notes:
-// Pass a negative number as the iteration to use the default value of 100_000
-// Even though you can pass as many boolean values for keepErrors only the first will be taken into account
-func RkRk(U, V *mat.Dense, y *mat.VecDense, iterations int, tolerance float64, keepErrors ...bool) (mat.VecDense, []float64) {
+// The two systems are coupled through the intermediate x of U*x=y. Every iteration projects x onto a random row
+// of U*x=y, then b onto a random row r of V*b=x, whose right-hand side is the current entry x_r. x has one entry
+// per column of U and r is a row of V, which is why V must have exactly one row per column of U.
+// Pass a negative number as the iteration to use the default value of 100_000, or 0 to get the starting point
+// back, as in RandomizedKaczmarz.
+// The i-th entry of the errors array is the squared residual after iteration i.
+// The algorithm stops as soon as the error drops to tolerance, so the length of the errors array is the
+// number of iterations actually performed.
+// If keepErrors is false the returned errors array is nil, and unless WithHistory is used the tolerance is never checked.
+// RkRkWithIntermediate also returns the intermediate x.
+func RkRk(U, V mat.Matrix, y *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+	_, b, errors, err := RkRkWithIntermediate(U, V, y, iterations, tolerance, keepErrors, opts...)
+
+	return b, errors, err
+}
+
+// RkRkWithIntermediate is RkRk that also returns the intermediate solution x of U*x=y, before the solution b of
+// A*b=y.
+//
+// Both vectors are allocated by the solve, so they never share their storage with the inputs, the initial guesses
+// or each other. Returns the same errors as RkRk, together with two empty vectors.
+func RkRkWithIntermediate(U, V mat.Matrix, y *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, mat.VecDense, []float64, error) {
 
 	// STEP 0.
 	// Initialization of variables
@@ -28,63 +50,103 @@ func RkRk(U, V *mat.Dense, y *mat.VecDense, iterations int, tolerance float64, k
 		iterations = 100_000
 	}
 
+	o := newOptions(opts)
+	src := o.source()
+
 	rowsU, colsU := U.Dims()
 	rowsV, colsV := V.Dims()
+	iterations = o.epochIterations(iterations, rowsU)
+	if err := checkLength(y, "y", rowsU, "U"); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	if err := checkCoupling(U, V); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(U, "U"); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(V, "V"); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(y, "y"); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
 
-	x := mat.NewVecDense(colsU, nil)
-	b := mat.NewVecDense(colsV, nil)
+	x, err := startingPoint(o.intermediate, "the initial intermediate", colsU, "the columns of U")
+	if err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	b, err := startingPoint(o.initialGuess, "the initial guess", colsV, "the columns of V")
+	if err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
 
-	var errors []float64
+	// Readers of the rows of U and V, which reuse the same vector for every row
+	readerU := newRowReader(U)
+	readerV := newRowReader(V)
 
-	// STEP 1.
-	// Computing the frobenius norm uf U and V
-	frobeniusU := FrobeniusSquared(U)
-	frobeniusV := FrobeniusSquared(V)
+	track, err := newTracker(o, tolerance, keepErrors, b, coupledResidualOf(U, V, b, y))
+	if err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
 
-	// STEP 2.
-	// Computing the probability of each row of U and V
+	// STEP 1.
+	// Computing the probability and the norm of each row of U and V
 	probsU := make([]float64, rowsU)
+	normsU := make([]float64, rowsU)
 	probsV := make([]float64, rowsV)
+	normsV := make([]float64, rowsV)
 
+	var frobeniusU, frobeniusV float64
 	waitGroup := sync.WaitGroup{}
 	waitGroup.Add(2)
-	go GetRowsProbability(probsU, frobeniusU, U, rowsU, &waitGroup)
-	go GetRowsProbability(probsV, frobeniusV, V, rowsV, &waitGroup)
+	go GetRowsProbability(probsU, normsU, &frobeniusU, U, rowsU, &waitGroup)
+	go GetRowsProbability(probsV, normsV, &frobeniusV, V, rowsV, &waitGroup)
 	waitGroup.Wait()
+	if err := checkNonZero(frobeniusU, "U"); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	if err := checkNonZero(frobeniusV, "V"); err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
 
-	// STEP 3.
+	samplerU, err := o.newSampler("row", "U", probsU, src)
+	if err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	samplerV, err := o.newSampler("row", "V", probsV, src)
+	if err != nil {
+		return mat.VecDense{}, mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
 	// Repeating the same process until we go insane
 	for i := 0; i < iterations; i++ {
-		randU := GetRandomRow(probsU)
-		randV := GetRandomRow(probsV)
+		randU := samplerU.Next()
+		randV := samplerV.Next()
 
-		chosenU := U.RowView(randU)
-		chosenV := V.RowView(randV)
+		chosenU := readerU.at(randU)
+		chosenV := readerV.at(randV)
 
-		euclideanU := EuclideanNormSquared(chosenU)
-		euclideanV := EuclideanNormSquared(chosenV)
+		normU := normsU[randU]
+		normV := normsV[randV]
 
 		x.AddScaledVec(
 			x,
-			(y.At(randU, 0)-mat.Dot(chosenU, x))/euclideanU,
+			o.relaxation*((y.At(randU, 0)-mat.Dot(chosenU, x))/normU)/normU,
 			chosenU)
 		b.AddScaledVec(
 			b,
-			(x.At(randV, 0)-mat.Dot(chosenV, b))/euclideanV,
+			o.relaxation*((x.At(randV, 0)-mat.Dot(chosenV, b))/normV)/normV,
 			chosenV)
 
-		if keepErrors[0] {
-			errVec := new(mat.VecDense)
-			errVec.MulVec(V, b)
-			errVec2 := new(mat.VecDense)
-			errVec2.MulVec(U, errVec)
-			errVec2.SubVec(errVec2, y)
-			errors = append(errors, EuclideanNormSquared(errVec2))
-			if errors[i] <= tolerance {
-				break
-			}
+		if track.record(i) {
+			break
 		}
 	}
 
-	return *b, errors
+	o.reportCoupledResidual(U, V, b, y)
+
+	return *x, *b, track.errors, track.err
 }
@@ -0,0 +1,216 @@
+package algorithms
+
+import (
+	"errors"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// coupledSystem returns U and V of a consistent system U*V*b=y with a unique solution, y and the solution b
+func coupledSystem() (*mat.Dense, *mat.Dense, *mat.VecDense, []float64) {
+	U := mat.NewDense(5, 3, []float64{
+		2, 1, 0,
+		1, 3, 1,
+		0, 1, 2,
+		1, 0, 1,
+		1, 1, 1,
+	})
+	V := mat.NewDense(3, 2, []float64{
+		1, 2,
+		3, 1,
+		1, 1,
+	})
+	want := []float64{1, -1}
+
+	x := mat.NewVecDense(3, nil)
+	x.MulVec(V, mat.NewVecDense(2, want))
+	y := mat.NewVecDense(5, nil)
+	y.MulVec(U, x)
+
+	return U, V, y, want
+}
+
+func TestRkRkWithoutErrors(t *testing.T) {
+	U, V, y, want := coupledSystem()
+
+	b, errs, err := RkRk(U, V, y, 5000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if errs != nil {
+		t.Errorf("errors = %v, want nil when they aren't kept", errs)
+	}
+	checkSolution(t, "b", b.RawVector().Data, want)
+
+	_, errs, err = RkRk(U, V, y, 100, 0, true, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(errs) != 100 {
+		t.Errorf("kept %d errors, want one for each of the 100 iterations", len(errs))
+	}
+}
+
+func TestRelaxation(t *testing.T) {
+	U, V, y, want := coupledSystem()
+
+	plain, plainErrs, _ := RkRk(U, V, y, 500, 0, true, WithSeed(1))
+	relaxed, relaxedErrs, _ := RkRk(U, V, y, 500, 0, true, WithSeed(1), WithRelaxation(1))
+	if !mat.Equal(&plain, &relaxed) || !reflect.DeepEqual(plainErrs, relaxedErrs) {
+		t.Error("RkRk with a relaxation of 1 differs from RkRk without relaxation")
+	}
+
+	A, b, _ := smallSystem()
+	x, xErrs, _ := RandomizedKaczmarz(A, b, 500, 0, true, WithSeed(1))
+	relaxedX, relaxedXErrs, _ := RandomizedKaczmarz(A, b, 500, 0, true, WithSeed(1), WithRelaxation(1))
+	if !mat.Equal(&x, &relaxedX) || !reflect.DeepEqual(xErrs, relaxedXErrs) {
+		t.Error("RandomizedKaczmarz with a relaxation of 1 differs from RandomizedKaczmarz without relaxation")
+	}
+
+	// Over-relaxation takes other steps but still converges
+	over, _, _ := RkRk(U, V, y, 5000, 0, false, WithSeed(1), WithRelaxation(1.5))
+	if mat.Equal(&plain, &over) {
+		t.Error("RkRk with a relaxation of 1.5 took the same steps as without relaxation")
+	}
+	checkSolution(t, "over-relaxed b", over.RawVector().Data, want)
+}
+
+func TestZeroRows(t *testing.T) {
+	U, V, y, want := coupledSystem()
+
+	// U gets a zero row, with a zero entry in y, and a zero column matching the zero row added to V
+	Uzero := mat.NewDense(6, 4, nil)
+	Uzero.Slice(0, 5, 0, 3).(*mat.Dense).Copy(U)
+	yzero := mat.NewVecDense(6, nil)
+	yzero.SliceVec(0, 5).(*mat.VecDense).CopyVec(y)
+	Vzero := mat.NewDense(4, 2, nil)
+	Vzero.Slice(0, 3, 0, 2).(*mat.Dense).Copy(V)
+
+	b, _, err := RkRk(Uzero, Vzero, yzero, 5000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, value := range b.RawVector().Data {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			t.Fatalf("b = %v holds a non-finite entry", b.RawVector().Data)
+		}
+	}
+	checkSolution(t, "b", b.RawVector().Data, want)
+
+	A := mat.NewDense(3, 2, []float64{1, 1, 0, 0, 1, -1})
+	x, _, err := RandomizedKaczmarz(A, mat.NewVecDense(3, []float64{3, 0, -1}), 1000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "x", x.RawVector().Data, []float64{1, 2})
+}
+
+func TestRkRkDimensionMismatch(t *testing.T) {
+	U, V, y, _ := coupledSystem()
+
+	systems := []struct {
+		name    string
+		U, V    *mat.Dense
+		y       *mat.VecDense
+		opts    []Option
+		message string
+	}{
+		{"short y", U, V, mat.NewVecDense(4, nil), nil, "y has 4 rows, expected 5 to match U"},
+		{"V too short", U, mat.NewDense(2, 2, nil), y, nil, "V has 2 rows, expected 3 to match the columns of U"},
+		{"V too long", U, mat.NewDense(4, 2, nil), y, nil, "V has 4 rows, expected 3 to match the columns of U"},
+		{"initial guess", U, V, y, []Option{WithInitialGuess(mat.NewVecDense(3, nil))}, "the initial guess has 3 rows"},
+		{"initial intermediate", U, V, y, []Option{WithInitialIntermediate(mat.NewVecDense(2, nil))}, "the initial intermediate has 2 rows"},
+	}
+
+	for _, system := range systems {
+		_, _, err := RkRk(system.U, system.V, system.y, 10, 0, false, system.opts...)
+		if !errors.Is(err, ErrDimensionMismatch) {
+			t.Errorf("%s: got error %v, want %v", system.name, err, ErrDimensionMismatch)
+		} else if !strings.Contains(err.Error(), system.message) {
+			t.Errorf("%s: error %q doesn't say %q", system.name, err, system.message)
+		}
+	}
+}
+
+func TestWarmStart(t *testing.T) {
+	U, V, y, want := coupledSystem()
+	b := mat.NewVecDense(2, want)
+	x := mat.NewVecDense(3, nil)
+	x.MulVec(V, b)
+
+	_, cold, _ := RkRk(U, V, y, 100_000, 1e-20, true, WithSeed(1))
+	_, warm, _ := RkRk(U, V, y, 100_000, 1e-20, true, WithSeed(1), WithInitialGuess(b), WithInitialIntermediate(x))
+	if len(warm) != 1 || len(cold) < 10 {
+		t.Errorf("warm start took %d iterations and cold start %d, want 1 and many more", len(warm), len(cold))
+	}
+
+	// A guess close to the solution still needs fewer iterations than a cold start
+	A, bA, wantA := smallSystem()
+	guess := mat.NewVecDense(3, []float64{wantA[0] + 1e-6, wantA[1], wantA[2]})
+	_, coldA, _ := RandomizedKaczmarz(A, bA, 100_000, 1e-24, true, WithSeed(1))
+	_, warmA, _ := RandomizedKaczmarz(A, bA, 100_000, 1e-24, true, WithSeed(1), WithInitialGuess(guess))
+	if len(warmA) >= len(coldA)/2 {
+		t.Errorf("warm start took %d iterations, cold start %d", len(warmA), len(coldA))
+	}
+
+	// The guess is copied, never modified
+	if guess.AtVec(0) != wantA[0]+1e-6 {
+		t.Error("the initial guess was modified by the solve")
+	}
+}
+
+func TestRkRkWithIntermediate(t *testing.T) {
+	U, V, y, want := coupledSystem()
+	intermediate := mat.NewVecDense(3, []float64{1, 1, 1})
+
+	x, b, _, err := RkRkWithIntermediate(U, V, y, 5000, 0, false, WithSeed(1), WithInitialIntermediate(intermediate))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "b", b.RawVector().Data, want)
+
+	// x solves U*x=y, and is V*b once b is found
+	wantX := mat.NewVecDense(3, nil)
+	wantX.MulVec(V, mat.NewVecDense(2, want))
+	checkSolution(t, "x", x.RawVector().Data, wantX.RawVector().Data)
+
+	// RkRk returns the same b
+	plain, _, _ := RkRk(U, V, y, 5000, 0, false, WithSeed(1), WithInitialIntermediate(intermediate))
+	if !mat.Equal(&b, &plain) {
+		t.Errorf("b = %v, RkRk returns %v", b.RawVector().Data, plain.RawVector().Data)
+	}
+
+	// The returned vectors are the caller's own
+	x.SetVec(0, 100)
+	if intermediate.AtVec(0) != 1 || b.AtVec(0) == 100 {
+		t.Error("the intermediate shares its storage with the initial intermediate or b")
+	}
+}
+
+// BenchmarkRkRk solves a 2000x2000 factored system for 10_000 iterations, with the row norms of U and V computed
+// once before the iterations
+func BenchmarkRkRk(bench *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	gaussian := func(n int) []float64 {
+		data := make([]float64, n)
+		for k := range data {
+			data[k] = rnd.NormFloat64()
+		}
+		return data
+	}
+	U := mat.NewDense(2000, 2000, gaussian(2000*2000))
+	V := mat.NewDense(2000, 2000, gaussian(2000*2000))
+	y := mat.NewVecDense(2000, gaussian(2000))
+
+	bench.ReportAllocs()
+	bench.ResetTimer()
+	for n := 0; n < bench.N; n++ {
+		if _, _, err := RkRk(U, V, y, 10_000, 0, false, WithSeed(1)); err != nil {
+			bench.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package algorithms
+
+import (
+	"context"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RandomizedKaczmarz returns the solution of a consistent system A*x=b using the randomized Kaczmarz
+// algorithm of Strohmer and Vershynin.
+//
+// Parameters:
+// A is a mat.Matrix representing the system. mat.Dense rows are read without copying.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithMomentum.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A, an ErrZeroMatrix if A is zero and an ErrNonFiniteInput if
+// A or b holds a NaN or an infinity. The other solvers check their inputs the same way, see WithInputCheck.
+//
+// Notes:
+// Pass a negative number as the iteration to use the default value of 100_000. With 0 iterations nothing is
+// projected: the starting point, the initial guess or zero, is returned as it is with no errors, after the inputs
+// and options were checked.
+// The i-th entry of the errors array is the squared residual after iteration i.
+// The algorithm stops as soon as the error drops to tolerance, so the length of the errors array is the
+// number of iterations actually performed.
+// If keepErrors is false the returned errors array is nil, and unless WithHistory is used the tolerance is never checked.
+func RandomizedKaczmarz(A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+	return randomizedKaczmarz(context.Background(), A, b, iterations, tolerance, keepErrors, opts)
+}
+
+// RandomizedKaczmarzCtx is RandomizedKaczmarz that can be cancelled through ctx.
+//
+// The context is checked every few iterations (see WithCheckInterval). Once it is done the solver stops and
+// returns the solution and errors computed so far together with ctx.Err().
+func RandomizedKaczmarzCtx(ctx context.Context, A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+	return randomizedKaczmarz(ctx, A, b, iterations, tolerance, keepErrors, opts)
+}
+
+func randomizedKaczmarz(ctx context.Context, A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts []Option) (mat.VecDense, []float64, error) {
+	rowsA, _ := A.Dims()
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	solver, err := NewSolver(A, opts...)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	return solver.solve(ctx, b, iterations, tolerance, keepErrors, nil)
+}
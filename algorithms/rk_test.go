@@ -0,0 +1,176 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"reflect"
+	"testing"
+)
+
+// smallSystem returns a consistent 4x3 system with a unique solution and its solution
+func smallSystem() (*mat.Dense, *mat.VecDense, []float64) {
+	A := mat.NewDense(4, 3, []float64{
+		4, 1, 0,
+		1, 3, 1,
+		0, 1, 2,
+		1, 1, 1,
+	})
+	want := []float64{1, 2, -1}
+	b := mat.NewVecDense(4, nil)
+	b.MulVec(A, mat.NewVecDense(3, want))
+
+	return A, b, want
+}
+
+func TestSeededRunsAreReproducible(t *testing.T) {
+	A, b, _ := smallSystem()
+
+	_, first, _ := RandomizedKaczmarz(A, b, 200, 0, true, WithSeed(42))
+	_, second, _ := RandomizedKaczmarz(A, b, 200, 0, true, WithSeed(42))
+	if !reflect.DeepEqual(first, second) {
+		t.Error("two RandomizedKaczmarz runs with the same seed have different errors")
+	}
+	_, other, _ := RandomizedKaczmarz(A, b, 200, 0, true, WithSeed(43))
+	if reflect.DeepEqual(first, other) {
+		t.Error("two RandomizedKaczmarz runs with different seeds have the same errors")
+	}
+
+	U, V, y, _ := coupledSystem()
+	_, first, _ = RkRk(U, V, y, 200, 0, true, WithSeed(42))
+	_, second, _ = RkRk(U, V, y, 200, 0, true, WithSeed(42))
+	if !reflect.DeepEqual(first, second) {
+		t.Error("two RkRk runs with the same seed have different errors")
+	}
+}
+
+func TestRandomizedKaczmarzCtxCancelled(t *testing.T) {
+	A, b := inconsistentSystem()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The solve is cancelled after 500 iterations and notices it at the next check, 100 iterations later at most
+	cancelAt := WithProgress(500, func(iter int, residual float64) {
+		cancel()
+	})
+	x, errs, err := RandomizedKaczmarzCtx(ctx, A, b, 1_000_000, 0, true, WithSeed(1), WithCheckInterval(100), cancelAt)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(errs) < 500 || len(errs) > 600 {
+		t.Errorf("%d errors kept, want the errors of the 500 to 600 iterations run before the cancellation", len(errs))
+	}
+	if x.Len() != 2 || x.AtVec(0) == 0 {
+		t.Errorf("x = %v, want the partial solution", x.RawVector().Data)
+	}
+}
+
+func TestInputsUnchanged(t *testing.T) {
+	A, b, _ := smallSystem()
+	x0 := mat.NewVecDense(3, []float64{1, 1, 1})
+	U, V, y, _ := coupledSystem()
+	b0 := mat.NewVecDense(2, []float64{1, 1})
+	intermediate := mat.NewVecDense(3, []float64{-1, 2, 0})
+
+	inputs := []mat.Matrix{A, b, x0, U, V, y, b0, intermediate}
+	snapshots := make([]*mat.Dense, len(inputs))
+	for k, input := range inputs {
+		snapshots[k] = mat.DenseCopyOf(input)
+	}
+
+	solves := []struct {
+		name  string
+		solve func() error
+	}{
+		{"RandomizedKaczmarz", func() error {
+			_, _, err := RandomizedKaczmarz(A, b, 100, 0, true, WithSeed(1), WithInitialGuess(x0))
+			return err
+		}},
+		{"WithIncrementalResidual", func() error {
+			_, _, err := RandomizedKaczmarz(A, b, 100, 0, true, WithSeed(1), WithInitialGuess(x0), WithIncrementalResidual(true))
+			return err
+		}},
+		{"RandomizedExtendedKaczmarz", func() error {
+			_, _, err := RandomizedExtendedKaczmarz(A, b, 100, 0, true, WithSeed(1), WithInitialGuess(x0))
+			return err
+		}},
+		{"AveragedKaczmarz", func() error {
+			_, _, err := AveragedKaczmarz(A, b, 3, 100, 0, true, WithSeed(1), WithInitialGuess(x0))
+			return err
+		}},
+		{"BlockRandomizedKaczmarz", func() error {
+			_, _, err := BlockRandomizedKaczmarz(A, b, 2, 100, 0, true, WithSeed(1), WithInitialGuess(x0))
+			return err
+		}},
+		{"RkRk", func() error {
+			_, _, err := RkRk(U, V, y, 100, 0, true, WithSeed(1), WithInitialGuess(b0), WithInitialIntermediate(intermediate))
+			return err
+		}},
+		{"RkRek", func() error {
+			_, _, err := RkRek(U, V, y, 100, 0, true, WithSeed(1), WithInitialGuess(b0), WithInitialIntermediate(intermediate))
+			return err
+		}},
+	}
+
+	for _, solve := range solves {
+		if err := solve.solve(); err != nil {
+			t.Fatalf("%s: unexpected error %v", solve.name, err)
+		}
+		for k, input := range inputs {
+			if !mat.Equal(input, snapshots[k]) {
+				t.Fatalf("%s: input %d was modified", solve.name, k)
+			}
+		}
+	}
+}
+
+func TestIterations(t *testing.T) {
+	A, b := inconsistentSystem()
+	x0 := mat.NewVecDense(2, []float64{3, -4})
+
+	solvers := []struct {
+		name  string
+		solve func(iterations int) (mat.VecDense, []float64, error)
+	}{
+		{"RandomizedKaczmarz", func(iterations int) (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, iterations, 0, true, WithSeed(1), WithInitialGuess(x0))
+		}},
+		{"RandomizedExtendedKaczmarz", func(iterations int) (mat.VecDense, []float64, error) {
+			return RandomizedExtendedKaczmarz(A, b, iterations, 0, true, WithSeed(1), WithInitialGuess(x0))
+		}},
+		{"AveragedKaczmarz", func(iterations int) (mat.VecDense, []float64, error) {
+			return AveragedKaczmarz(A, b, 2, iterations, 0, true, WithSeed(1), WithInitialGuess(x0))
+		}},
+		{"BlockRandomizedKaczmarz", func(iterations int) (mat.VecDense, []float64, error) {
+			return BlockRandomizedKaczmarz(A, b, 2, iterations, 0, true, WithSeed(1), WithInitialGuess(x0))
+		}},
+		{"SamplingKaczmarzMotzkin", func(iterations int) (mat.VecDense, []float64, error) {
+			return SamplingKaczmarzMotzkin(A, b, 2, iterations, 0, true, WithSeed(1), WithInitialGuess(x0))
+		}},
+	}
+
+	cases := []struct {
+		name       string
+		iterations int
+		errors     int
+	}{
+		{"zero iterations return the starting point", 0, 0},
+		{"negative iterations use the default budget", -1, 100_000},
+		{"any negative count is the default", -20, 100_000},
+	}
+
+	for _, solver := range solvers {
+		for _, c := range cases {
+			x, errs, err := solver.solve(c.iterations)
+			if err != nil {
+				t.Fatalf("%s, %s: unexpected error %v", solver.name, c.name, err)
+			}
+			if len(errs) != c.errors {
+				t.Errorf("%s, %s: %d iterations, want %d", solver.name, c.name, len(errs), c.errors)
+			}
+			if c.iterations == 0 && !mat.Equal(&x, x0) {
+				t.Errorf("%s, %s: x = %v, want %v", solver.name, c.name, x.RawVector().Data, x0.RawVector().Data)
+			}
+		}
+	}
+}
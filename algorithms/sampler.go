@@ -0,0 +1,266 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// indexSampler chooses the next row, column or block an algorithm projects on
+type indexSampler interface {
+	Next() int
+}
+
+// RowSampler chooses the rows RandomizedKaczmarz projects on, see WithRowSampler.
+//
+// Next returns the row of A to project on next, which must be a non-zero row. Reset is called at the start of
+// every solve, so that a sampler replaying a sequence starts it over.
+type RowSampler interface {
+	Next() int
+	Reset()
+}
+
+// IterateSampler is a RowSampler whose choice depends on the current solution, like GreedySampler.
+// The solver passes its x to Observe before every call to Next; x must only be read and not kept.
+type IterateSampler interface {
+	RowSampler
+	Observe(x mat.Vector)
+}
+
+// NewNormSampler returns a sampler drawing the rows of A with probability proportional to their squared norms,
+// the sampling of Strohmer and Vershynin that the solvers use by default. The rows are drawn from src, or from
+// the global source if src is nil. It panics if A is zero.
+func NewNormSampler(A mat.Matrix, src rand.Source) *AliasSampler {
+	return NewAliasSampler(RowProbabilities(A), src)
+}
+
+// NewUniformSampler returns a sampler drawing the n rows of a matrix with the same probability from src, or from
+// the global source if src is nil.
+func NewUniformSampler(n int, src rand.Source) *AliasSampler {
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return NewAliasSampler(weights, src)
+}
+
+// GreedySampler chooses the row of A*x=b whose hyperplane is the farthest from x, the rule of Motzkin's method.
+//
+// Every choice computes the whole residual b-A*x, which costs a matrix-vector product. No randomness is involved:
+// the same solve always chooses the same rows.
+type GreedySampler struct {
+	A        mat.Matrix
+	b        *mat.VecDense
+	norms    []float64
+	residual *mat.VecDense
+	x        *mat.VecDense
+	observed bool
+}
+
+// NewGreedySampler prepares the greedy choice of the rows of A*x=b. Like the mat package, it panics if b doesn't
+// have as many rows as A.
+func NewGreedySampler(A mat.Matrix, b *mat.VecDense) *GreedySampler {
+	rows, cols := A.Dims()
+	probs := make([]float64, rows)
+	norms := make([]float64, rows)
+
+	rowsProbability(probs, norms, A)
+
+	return &GreedySampler{
+		A:        A,
+		b:        b,
+		norms:    norms,
+		residual: mat.NewVecDense(rows, nil),
+		x:        mat.NewVecDense(cols, nil),
+	}
+}
+
+// Observe copies the current solution x, which the next call to Next reads. x isn't kept, so the solver is free to
+// update it afterwards.
+func (s *GreedySampler) Observe(x mat.Vector) {
+	s.x.CopyVec(x)
+	s.observed = true
+}
+
+// Next returns the non-zero row with the largest distance |b_i-a_i*x|/||a_i|| from x to its hyperplane, or the
+// first non-zero row if no solution was observed yet
+func (s *GreedySampler) Next() int {
+	best, farthest := -1, -1.0
+	if s.observed {
+		s.residual.MulVec(s.A, s.x)
+		s.residual.SubVec(s.b, s.residual)
+	}
+	for i, norm := range s.norms {
+		if norm == 0 {
+			continue
+		}
+		distance := math.Abs(s.residual.AtVec(i)) / norm
+		if distance > farthest {
+			best, farthest = i, distance
+		}
+	}
+
+	return best
+}
+
+// Reset forgets the observed solution
+func (s *GreedySampler) Reset() {
+	s.observed = false
+	s.residual.Zero()
+}
+
+// AliasSampler draws indices with probability proportional to a set of weights using Walker's alias method.
+//
+// Building the sampler costs O(n) and every draw costs O(1) with exactly two uniform numbers, so a sampler built
+// once from the row probabilities can be reused for all the iterations of an algorithm.
+type AliasSampler struct {
+	prob  []float64
+	alias []int
+	rnd   *rand.Rand
+}
+
+// NewAliasSampler builds the alias table for weights.
+//
+// The weights must be non-negative with a positive sum; they don't need to be normalized. Indices with a zero
+// weight are never drawn. The indices are drawn from src, or from the global source if src is nil.
+// Like the mat package, it panics if no weight is positive or a weight is NaN; the solvers check the weights of
+// their samplers first and return an error instead.
+func NewAliasSampler(weights []float64, src rand.Source) *AliasSampler {
+	n := len(weights)
+
+	sum := 0.0
+	for _, weight := range weights {
+		sum += weight
+	}
+	if !(sum > 0) {
+		panic("algorithms: weights must have a positive sum")
+	}
+
+	sampler := &AliasSampler{
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+	}
+	if src != nil {
+		sampler.rnd = rand.New(src)
+	}
+
+	// Scale the weights so that their mean is 1 and split them into the ones below and above the mean
+	scaled := make([]float64, n)
+	var small, large []int
+	positive := 0
+	for i, weight := range weights {
+		scaled[i] = weight * float64(n) / sum
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+		if weight > 0 {
+			positive = i
+		}
+	}
+
+	// Every small index is topped up to 1 with the excess of a large one
+	for len(small) > 0 && len(large) > 0 {
+		less := small[len(small)-1]
+		small = small[:len(small)-1]
+		more := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		sampler.prob[less] = scaled[less]
+		sampler.alias[less] = more
+
+		scaled[more] = scaled[more] + scaled[less] - 1
+		if scaled[more] < 1 {
+			small = append(small, more)
+		} else {
+			large = append(large, more)
+		}
+	}
+
+	// Whatever is left is 1 up to rounding errors, except for zero weights that must never be drawn
+	for _, i := range large {
+		sampler.prob[i] = 1
+	}
+	for _, i := range small {
+		if weights[i] > 0 {
+			sampler.prob[i] = 1
+		} else {
+			sampler.prob[i] = 0
+			sampler.alias[i] = positive
+		}
+	}
+
+	return sampler
+}
+
+// Reset does nothing, since every draw is independent of the previous ones
+func (s *AliasSampler) Reset() {}
+
+// Next returns a random index with probability proportional to its weight
+func (s *AliasSampler) Next() int {
+	var i int
+	var u float64
+	if s.rnd == nil {
+		i = rand.Intn(len(s.prob))
+		u = rand.Float64()
+	} else {
+		i = s.rnd.Intn(len(s.prob))
+		u = s.rnd.Float64()
+	}
+
+	if u < s.prob[i] {
+		return i
+	}
+
+	return s.alias[i]
+}
+
+// orderedSampler steps through the indices with a positive weight, either in order or in a new random order
+// for every pass, see Cyclic and RandomPermutation.
+type orderedSampler struct {
+	order   []int
+	next    int
+	shuffle bool
+	rnd     *rand.Rand
+}
+
+// newOrderedSampler builds a sampler stepping through the indices with a positive weight.
+//
+// If shuffle is set the order is shuffled before every pass with src, or with the global source if src is nil.
+func newOrderedSampler(weights []float64, shuffle bool, src rand.Source) *orderedSampler {
+	sampler := &orderedSampler{shuffle: shuffle}
+	for i, weight := range weights {
+		if weight > 0 {
+			sampler.order = append(sampler.order, i)
+		}
+	}
+	if len(sampler.order) == 0 {
+		panic("algorithms: weights must have a positive sum")
+	}
+	if src != nil {
+		sampler.rnd = rand.New(src)
+	}
+
+	return sampler
+}
+
+// Next returns the next index of the current pass, starting a new pass once every index was returned
+func (s *orderedSampler) Next() int {
+	if s.next == 0 && s.shuffle {
+		swap := func(i, j int) {
+			s.order[i], s.order[j] = s.order[j], s.order[i]
+		}
+		if s.rnd == nil {
+			rand.Shuffle(len(s.order), swap)
+		} else {
+			s.rnd.Shuffle(len(s.order), swap)
+		}
+	}
+
+	i := s.order[s.next]
+	s.next = (s.next + 1) % len(s.order)
+
+	return i
+}
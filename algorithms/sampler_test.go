@@ -0,0 +1,155 @@
+package algorithms
+
+import (
+	"errors"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+func TestAliasSamplerFrequencies(t *testing.T) {
+	weights := []float64{1, 0, 3, 6}
+	sampler := NewAliasSampler(weights, rand.NewSource(1))
+
+	const draws = 100_000
+	counts := make([]int, len(weights))
+	for k := 0; k < draws; k++ {
+		counts[sampler.Next()]++
+	}
+
+	for i, weight := range weights {
+		if got, want := float64(counts[i])/draws, weight/10; math.Abs(got-want) > 0.01 {
+			t.Errorf("index %d drawn with frequency %g, want %g", i, got, want)
+		}
+	}
+}
+
+func TestNonFiniteSamplingWeights(t *testing.T) {
+	A := mat.NewDense(3, 2, []float64{1, 0, math.NaN(), 1, 0, 1})
+	b := mat.NewVecDense(3, []float64{1, 1, 1})
+
+	solvers := []struct {
+		name  string
+		solve func() (mat.VecDense, []float64, error)
+	}{
+		{"RandomizedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 10, 0, false, WithInputCheck(false))
+		}},
+		{"RandomizedExtendedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return RandomizedExtendedKaczmarz(A, b, 10, 0, false, WithInputCheck(false))
+		}},
+		{"BlockRandomizedKaczmarz", func() (mat.VecDense, []float64, error) {
+			return BlockRandomizedKaczmarz(A, b, 2, 10, 0, false, WithInputCheck(false))
+		}},
+		{"Cyclic", func() (mat.VecDense, []float64, error) {
+			return RandomizedKaczmarz(A, b, 10, 0, false, WithInputCheck(false), WithSamplingStrategy(Cyclic))
+		}},
+	}
+
+	for _, solver := range solvers {
+		if _, _, err := solver.solve(); !errors.Is(err, ErrNonFiniteInput) {
+			t.Errorf("%s: got error %v, want %v", solver.name, err, ErrNonFiniteInput)
+		}
+	}
+}
+
+func TestZeroSamplingWeights(t *testing.T) {
+	A := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	b := mat.NewVecDense(2, []float64{1, 1})
+
+	// The weights sum to +Inf and would normalize to zero on every row
+	huge := []float64{math.MaxFloat64, math.MaxFloat64}
+	if _, _, err := RandomizedKaczmarz(A, b, 10, 0, false, WithRowProbabilities(huge)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidOption)
+	}
+
+	// Only the zero row has a weight
+	zero := mat.NewDense(2, 2, []float64{1, 0, 0, 0})
+	if _, _, err := RandomizedKaczmarz(zero, b, 10, 0, false, WithRowProbabilities([]float64{0, 1})); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidOption)
+	}
+}
+
+// scriptedSampler replays rows in order, starting over at every Reset
+type scriptedSampler struct {
+	rows   []int
+	next   int
+	resets int
+}
+
+func (s *scriptedSampler) Next() int {
+	row := s.rows[s.next%len(s.rows)]
+	s.next++
+
+	return row
+}
+
+func (s *scriptedSampler) Reset() {
+	s.next = 0
+	s.resets++
+}
+
+func TestRowSampler(t *testing.T) {
+	A, b, _ := smallSystem()
+	sampler := &scriptedSampler{rows: []int{2, 0, 3, 1, 2}}
+
+	x, _, err := RandomizedKaczmarz(A, b, 5, 0, false, WithRowSampler(sampler))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if sampler.resets != 1 || sampler.next != 5 {
+		t.Errorf("the sampler was reset %d times and drawn %d times, want 1 and 5", sampler.resets, sampler.next)
+	}
+
+	// The same projections done by hand, in the scripted order
+	want := mat.NewVecDense(3, nil)
+	for _, row := range sampler.rows {
+		a := A.RowView(row)
+		want.AddScaledVec(want, (b.AtVec(row)-mat.Dot(a, want))/mat.Dot(a, a), a)
+	}
+	if !mat.EqualApprox(&x, want, 1e-12) {
+		t.Errorf("x = %v, want %v", x.RawVector().Data, want.RawVector().Data)
+	}
+
+	// A second solve starts the script over
+	again, _, _ := RandomizedKaczmarz(A, b, 5, 0, false, WithRowSampler(sampler))
+	if !mat.Equal(&x, &again) {
+		t.Error("the scripted sampler wasn't reset for the second solve")
+	}
+}
+
+func TestGreedySampler(t *testing.T) {
+	A := mat.NewDense(3, 2, []float64{1, 0, 0, 2, 1, 1})
+	b := mat.NewVecDense(3, []float64{1, 4, 3})
+	sampler := NewGreedySampler(A, b)
+
+	// From x = 0 the distances to the hyperplanes are 1, 2 and 3/sqrt(2)
+	// The observed x is only borrowed: changing it afterwards doesn't move the sampler's copy
+	observed := mat.NewVecDense(2, nil)
+	sampler.Observe(observed)
+	observed.SetVec(1, 2)
+	if row := sampler.Next(); row != 2 {
+		t.Errorf("chose row %d, want the farthest row 2", row)
+	}
+
+	x, _, err := RandomizedKaczmarz(A, b, 200, 0, false, WithRowSampler(sampler))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "x", x.RawVector().Data, []float64{1, 2})
+}
+
+func TestUniformSampler(t *testing.T) {
+	sampler := NewUniformSampler(4, rand.NewSource(1))
+
+	counts := make([]int, 4)
+	for k := 0; k < 40_000; k++ {
+		counts[sampler.Next()]++
+	}
+	for i, count := range counts {
+		if math.Abs(float64(count)/40_000-0.25) > 0.01 {
+			t.Errorf("row %d drawn %d times out of 40000, want about a quarter", i, count)
+		}
+	}
+}
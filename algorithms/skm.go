@@ -0,0 +1,123 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// SamplingKaczmarzMotzkin returns the solution of a consistent system A*x=b using the sampling Kaczmarz-Motzkin
+// algorithm of De Loera, Haddock and Needell.
+//
+// Every iteration samples beta rows and projects x onto the hyperplane of the one that x is farthest from.
+// beta interpolates between RandomizedKaczmarz, which it matches exactly for beta = 1 and the same seed,
+// and the greedy Motzkin method, which it approaches as beta grows past the number of rows.
+//
+// Parameters:
+// A is a mat.Matrix representing the system. mat.Dense rows are read without copying.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// beta is the number of rows sampled at every iteration.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// A beta smaller than 1 is treated as 1.
+// The rows are sampled with replacement from the same distribution as RandomizedKaczmarz, so even a beta equal
+// to the number of rows may miss the greediest row.
+func SamplingKaczmarzMotzkin(A mat.Matrix, b *mat.VecDense, beta, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+	if beta < 1 {
+		beta = 1
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// Reader of the rows of A, which reuses the same vector for every row
+	readerA := newRowReader(A)
+
+	track, err := newTracker(o, tolerance, keepErrors, x, residualOf(A, x, b))
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each row of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+
+	frobeniusA := rowsProbability(probsA, normsA, A)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
+	// Projecting x onto the hyperplane of the farthest of beta randomly chosen rows
+	for i := 0; i < iterations; i++ {
+		randA := -1
+		var residualA, farthest float64
+		for k := 0; k < beta; k++ {
+			row := samplerA.Next()
+			residual := b.AtVec(row) - mat.Dot(readerA.at(row), x)
+
+			// The distance from x to the hyperplane of the row
+			distance := math.Abs(residual) / normsA[row]
+			if randA == -1 || distance > farthest {
+				randA, residualA, farthest = row, residual, distance
+			}
+		}
+
+		chosenA := readerA.at(randA)
+
+		x.AddScaledVec(
+			x,
+			o.relaxation*(residualA/normsA[randA])/normsA[randA],
+			chosenA)
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
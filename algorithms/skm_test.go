@@ -0,0 +1,36 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"testing"
+)
+
+func TestSamplingKaczmarzMotzkin(t *testing.T) {
+	A, b, _ := illConditionedSystem()
+
+	// A single sampled row is the row RandomizedKaczmarz would project on
+	x, errs, err := SamplingKaczmarzMotzkin(A, b, 1, 500, 0, true, WithSeed(3))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	plain, plainErrs, _ := RandomizedKaczmarz(A, b, 500, 0, true, WithSeed(3))
+	if !mat.EqualApprox(&x, &plain, 1e-12) {
+		t.Errorf("x = %v with beta = 1, want the RandomizedKaczmarz %v", x.RawVector().Data, plain.RawVector().Data)
+	}
+	for i := range plainErrs {
+		if math.Abs(errs[i]-plainErrs[i]) > 1e-12*math.Max(1, plainErrs[i]) {
+			t.Fatalf("error %d is %g with beta = 1, want the RandomizedKaczmarz %g", i, errs[i], plainErrs[i])
+		}
+	}
+}
+
+func TestSamplingKaczmarzMotzkinGreedy(t *testing.T) {
+	A, b, want := smallSystem()
+
+	x, _, err := SamplingKaczmarzMotzkin(A, b, 4, 2000, 0, false, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "x", x.RawVector().Data, want)
+}
@@ -0,0 +1,515 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"sync"
+	"time"
+)
+
+// Solver solves many systems A*x=b that share the same matrix A with the randomized Kaczmarz algorithm.
+//
+// The norms and the probabilities of the rows of A are computed once by NewSolver and reused by every call to
+// Solve, so only the iterations are paid for every right-hand side.
+//
+// A Solver is never modified after NewSolver returns, except for a rank estimate computed once on demand, so Solve
+// may be called from many goroutines at once. Every solve builds its own row sampler with its own random source
+// and its own buffers; A is only read.
+type Solver struct {
+	matrix mat.Matrix
+	o      *options
+	normsA []float64
+	probsA []float64
+	// gram is the Gram matrix A*A^T, only kept for WithGramCache
+	gram *mat.Dense
+	// rank holds whether A looks rank deficient and its condition number, estimated by the first solve that
+	// uses WithRankCheck
+	rank          sync.Once
+	rankDeficient bool
+	cond          float64
+}
+
+// NewSolver prepares the randomized Kaczmarz solve of systems with the matrix A.
+//
+// Parameters:
+// A is a mat.Matrix representing the systems. mat.Dense rows are read without copying, so A must not be
+// modified while the solver is in use.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities. They apply to every solve.
+//
+// Returns the solver, or an ErrInvalidOption if the row probabilities or the row scaling don't fit A, an
+// ErrZeroMatrix if A is zero and an ErrNonFiniteInput if A holds a NaN or an infinity.
+func NewSolver(A mat.Matrix, opts ...Option) (*Solver, error) {
+	o := newOptions(opts)
+	rowsA, _ := A.Dims()
+	if err := o.checkFinite(A, "A"); err != nil {
+		return nil, err
+	}
+
+	// Computing the probability and the norm of each row of A
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+
+	frobeniusA := rowsProbability(probsA, normsA, A)
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return nil, err
+	}
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return nil, err
+	}
+
+	var gram *mat.Dense
+	if rowsA <= o.gramRows {
+		gram = mat.NewDense(rowsA, rowsA, nil)
+		gram.Mul(A, A.T())
+	}
+
+	return &Solver{
+		matrix: A,
+		o:      o,
+		normsA: normsA,
+		probsA: probsA,
+		gram:   gram,
+	}, nil
+}
+
+// Solve returns the solution of A*x=b, see RandomizedKaczmarz for the parameters and the errors.
+//
+// opts are settings for this solve only, such as WithInitialGuess or WithHistory, applied over those given
+// to NewSolver. The row probabilities, WithRowProbabilities and WithRowScaling, are fixed by NewSolver and
+// ignored here, but WithSeed and WithSamplingStrategy can differ from one solve to the next.
+//
+// Solve is safe for concurrent use as long as no one modifies A or b. Each solve draws its rows from a source of
+// its own: with a seed, a solve chooses the same rows whatever the other solves do, so solving the same system
+// twice gives the same result; without one, the rows are drawn from the global source, which is safe for
+// concurrent use. Options given to a concurrent solve, like WithHistory or WithStopReason, must not point to
+// the same variables as those of another.
+func (s *Solver) Solve(b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+	rowsA, _ := s.matrix.Dims()
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	return s.solve(context.Background(), b, iterations, tolerance, keepErrors, opts)
+}
+
+// SolveState is a solve of A*x=b in progress, which Solver.Continue runs for more iterations.
+//
+// It holds the current x, the state of the row sampler and the errors tracked so far, so continuing a solve
+// for n and then m iterations gives the same result as solving for n+m iterations at once. A SolveState must
+// not be used by several goroutines at once.
+type SolveState struct {
+	solver   *Solver
+	b        *mat.VecDense
+	x        *mat.VecDense
+	o        *options
+	track    *tracker
+	readerA  *rowReader
+	samplerA indexSampler
+	// observer is the sampler given to WithRowSampler, if it needs to see x
+	observer IterateSampler
+	adaptive *adaptiveRows
+	// previous and moved are the previous iterate and the last move of x, only needed for the momentum term
+	previous *mat.VecDense
+	moved    *mat.VecDense
+	// window holds the last rows drawn, only needed for WithRecentRows
+	window *rowWindow
+	// u holds sqrt(lambda) times the auxiliary vector of the regularized system, only needed for WithTikhonov
+	u []float64
+	// residual is b-A*x, updated with the rows of the Gram matrix A*A^T, only needed for WithIncrementalResidual
+	residual      *mat.VecDense
+	readerGram    *rowReader
+	resetResidual func()
+	// rankDeficient is set if WithRankCheck found A rank deficient
+	rankDeficient bool
+	// done is the number of iterations run so far and elapsed the time they took
+	done    int
+	elapsed time.Duration
+}
+
+// Iterations returns the number of iterations run so far
+func (state *SolveState) Iterations() int {
+	return state.done
+}
+
+// Reason returns why the last run of the solve stopped
+func (state *SolveState) Reason() StopReason {
+	return state.track.reason
+}
+
+// Start prepares the solve of A*x=b without running any iteration, see Solve for the parameters and Continue to
+// run it.
+//
+// Returns an ErrDimensionMismatch if b doesn't have as many rows as A and an ErrInvalidOption if the options
+// don't fit the system.
+func (s *Solver) Start(b *mat.VecDense, tolerance float64, keepErrors bool, opts ...Option) (*SolveState, error) {
+	rowsA, _ := s.matrix.Dims()
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return nil, err
+	}
+
+	return s.start(b, tolerance, keepErrors, opts)
+}
+
+// Continue runs extraIters more iterations of a solve prepared by Start and returns the new x together with all
+// the errors tracked since Start.
+//
+// Parameters:
+// state is the solve to continue, prepared by Start on this solver. It is updated in place.
+// extraIters is an int representing how many more times MAX is the algorithm allowed to run. Pass a negative
+// number to use the default value of 100_000, or 0 to leave the solve as it is.
+//
+// Returns the vector x and a []float64 array containing the errors since the start of the solve, as Solve does.
+// An ErrInvalidOption is returned if state belongs to another solver.
+//
+// Notes:
+// The iterations are numbered from the start of the solve, so WithHistory and WithProgress go on where they
+// stopped instead of starting over, and the history keeps growing. The time spent between two calls doesn't
+// count towards WithTimeout or the elapsed times of the history.
+// A solve that reached the tolerance or stopped because x wasn't finite doesn't run again. A solve that ran out of
+// iterations or time, stagnated or was cancelled goes on.
+func (s *Solver) Continue(state *SolveState, extraIters int) (mat.VecDense, []float64, error) {
+	if state.solver != s {
+		return mat.VecDense{}, nil, fmt.Errorf("%w: the solve state was started by another solver", ErrInvalidOption)
+	}
+	if extraIters < 0 {
+		extraIters = 100_000
+	}
+
+	err := s.run(context.Background(), state, extraIters)
+	state.o.reportResidual(s.matrix, state.track.solution(), state.b)
+
+	return *mat.VecDenseCopyOf(state.track.solution()), state.track.errors, err
+}
+
+// solve runs the randomized Kaczmarz iterations for b, stopping early if ctx is done
+func (s *Solver) solve(ctx context.Context, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts []Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	state, err := s.start(b, tolerance, keepErrors, opts)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	rowsA, _ := s.matrix.Dims()
+	iterations = state.o.epochIterations(iterations, rowsA)
+
+	// STEP 1.
+	// Projecting x onto the hyperplane of a randomly chosen row
+	err = s.run(ctx, state, iterations)
+	state.o.reportResidual(s.matrix, state.track.solution(), b)
+
+	return *state.track.solution(), state.track.errors, err
+}
+
+// start prepares the solve of A*x=b, whose length was already checked
+func (s *Solver) start(b *mat.VecDense, tolerance float64, keepErrors bool, opts []Option) (*SolveState, error) {
+	o := new(options)
+	*o = *s.o
+	// The counted samplers belong to this solve only
+	o.sampled = nil
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return nil, err
+	}
+	if o.recentRows > 1 && o.lambda != 0 {
+		return nil, fmt.Errorf("%w: WithRecentRows and WithTikhonov can't be combined", ErrInvalidOption)
+	}
+	if o.rankDeficient != nil {
+		s.rank.Do(func() {
+			s.rankDeficient, s.cond = estimateRank(s.matrix)
+		})
+		o.reportRank(s.rankDeficient, s.cond, "A")
+	}
+
+	A := s.matrix
+	rowsA, colsA := A.Dims()
+	// The epochs of a solve continued by Continue, which never calls epochIterations
+	o.epochSize = rowsA
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return nil, err
+	}
+
+	state := &SolveState{
+		solver: s,
+		b:      b,
+		x:      x,
+		o:      o,
+		// Reader of the rows of A, which reuses the same vector for every row
+		readerA:       newRowReader(A),
+		rankDeficient: o.rankDeficient != nil && s.rankDeficient,
+	}
+
+	residual := residualOf(A, x, b)
+	if (o.incremental || s.gram != nil) && o.momentum == 0 && !o.nonnegative && o.recentRows < 2 {
+		gram := s.gram
+		if gram == nil {
+			gram = mat.NewDense(rowsA, rowsA, nil)
+			gram.Mul(A, A.T())
+		}
+		state.readerGram = newRowReader(gram)
+
+		state.residual = mat.NewVecDense(rowsA, nil)
+		state.resetResidual = func() {
+			state.residual.MulVec(A, x)
+			state.residual.SubVec(b, state.residual)
+		}
+		residual = func() float64 {
+			return EuclideanNormSquared(state.residual)
+		}
+	}
+
+	state.track, err = newTracker(o, tolerance, keepErrors, x, residual)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sampler is replaced by one built from the residual when the sampling adapts
+	src := o.source()
+	if o.rowSampler != nil {
+		o.rowSampler.Reset()
+		state.samplerA = o.countDraws("row", "A", s.probsA, o.rowSampler)
+		state.observer, _ = o.rowSampler.(IterateSampler)
+	} else {
+		if state.samplerA, err = o.newSampler("row", "A", s.probsA, src); err != nil {
+			return nil, err
+		}
+		if o.adaptiveEvery > 0 {
+			state.adaptive = newAdaptiveRows(A, b, s.normsA, src)
+		}
+	}
+
+	if o.nonnegative {
+		clampNegative(x.RawVector().Data)
+	}
+	if state.residual != nil {
+		state.resetResidual()
+	}
+
+	if o.lambda != 0 {
+		state.u = make([]float64, len(s.normsA))
+	}
+
+	if o.recentRows > 1 {
+		state.window = newRowWindow(o.recentRows, colsA)
+	}
+
+	if o.momentum != 0 {
+		state.previous = mat.NewVecDense(colsA, nil)
+		state.previous.CopyVec(x)
+		state.moved = mat.NewVecDense(colsA, nil)
+	}
+
+	return state, nil
+}
+
+// run projects x onto the hyperplanes of randomly chosen rows for at most iterations more iterations,
+// stopping early if ctx is done
+func (s *Solver) run(ctx context.Context, state *SolveState, iterations int) error {
+	o, x, b, track := state.o, state.x, state.b, state.track
+	if track.err != nil {
+		return track.err
+	}
+	if track.reason == StopTolerance {
+		return nil
+	}
+
+	track.resume(state.elapsed)
+	defer func() {
+		state.elapsed = time.Since(track.start)
+	}()
+	defer o.logSampling()
+
+	for n := 0; n < iterations; n++ {
+		i := state.done
+		if i%o.checkInterval == 0 && ctx.Err() != nil {
+			track.stop(contextReason(ctx))
+			return ctx.Err()
+		}
+
+		if state.adaptive != nil && i > 0 && i%o.adaptiveEvery == 0 {
+			if sampler := state.adaptive.sampler(x); sampler != nil {
+				state.samplerA = replaceSampler(state.samplerA, sampler)
+			}
+		}
+
+		if state.observer != nil {
+			state.observer.Observe(x)
+		}
+		randA := state.samplerA.Next()
+
+		chosenA := state.readerA.at(randA)
+
+		normA := s.normsA[randA]
+
+		if o.momentum != 0 {
+			state.moved.SubVec(x, state.previous)
+			state.previous.CopyVec(x)
+		}
+
+		// b_i-a_i*x, read off the residual when it is kept up to date
+		var rowResidual float64
+		if state.residual != nil {
+			rowResidual = state.residual.AtVec(randA)
+		} else {
+			rowResidual = b.AtVec(randA) - mat.Dot(chosenA, x)
+		}
+
+		var step float64
+		if state.window != nil {
+			state.window.project(state.readerA, b, x, randA, o.relaxation)
+		} else if o.lambda != 0 {
+			// Projecting onto the row [a_i sqrt(lambda)*e_i] of the regularized system, whose squared norm
+			// ||a_i||^2+lambda is never formed so that it can't overflow
+			step = o.relaxation * ((rowResidual - state.u[randA]) / normA) / (normA + o.lambda/normA)
+			state.u[randA] += o.lambda * step
+		} else {
+			step = o.relaxation * (rowResidual / normA) / normA
+		}
+		if state.window == nil {
+			x.AddScaledVec(x, step, chosenA)
+		}
+
+		if state.residual != nil {
+			if (i+1)%len(s.normsA) == 0 {
+				state.resetResidual()
+			} else {
+				state.residual.AddScaledVec(state.residual, -step, state.readerGram.at(randA))
+			}
+		}
+
+		if o.momentum != 0 {
+			x.AddScaledVec(x, o.momentum, state.moved)
+		}
+		if o.nonnegative {
+			clampNegative(x.RawVector().Data)
+		}
+
+		state.done++
+		if track.record(i) {
+			break
+		}
+	}
+
+	return track.err
+}
+
+// adaptiveRows builds samplers drawing the rows of A proportionally to their squared residual, see WithAdaptiveSampling
+type adaptiveRows struct {
+	A        mat.Matrix
+	b        *mat.VecDense
+	normsA   []float64
+	src      rand.Source
+	residual *mat.VecDense
+	weights  []float64
+}
+
+// newAdaptiveRows prepares the adaptive sampling of the rows of A, drawing from src or the global source if src is nil
+func newAdaptiveRows(A mat.Matrix, b *mat.VecDense, normsA []float64, src rand.Source) *adaptiveRows {
+	return &adaptiveRows{
+		A:        A,
+		b:        b,
+		normsA:   normsA,
+		src:      src,
+		residual: mat.NewVecDense(len(normsA), nil),
+		weights:  make([]float64, len(normsA)),
+	}
+}
+
+// sampler returns a sampler for the squared residuals of x, or nil if no non-zero row has a residual
+func (a *adaptiveRows) sampler(x *mat.VecDense) indexSampler {
+	a.residual.MulVec(a.A, x)
+	a.residual.SubVec(a.b, a.residual)
+
+	// The residuals are squared relative to the norm of the residual so that they don't overflow
+	norm := EuclideanNorm(a.residual)
+	sum := 0.0
+	for row := range a.weights {
+		a.weights[row] = 0
+		// Zero rows are never chosen, even with a residual, since there's nothing to project on
+		if a.normsA[row] > 0 && norm > 0 {
+			ratio := a.residual.AtVec(row) / norm
+			a.weights[row] = ratio * ratio
+			sum += a.weights[row]
+		}
+	}
+	if !(sum > 0) {
+		return nil
+	}
+
+	return NewAliasSampler(a.weights, a.src)
+}
+
+// rowWindow projects x onto the hyperplanes of the last rows drawn, see WithRecentRows
+type rowWindow struct {
+	// rows are the last rows drawn, from the oldest at next onwards once the window is full
+	rows []int
+	next int
+	// basis and rhs are the orthonormalized rows of the window and their entries of b, rebuilt at every step
+	basis []*mat.VecDense
+	rhs   []float64
+}
+
+// newRowWindow prepares a window of the last k rows of a matrix with cols columns
+func newRowWindow(k, cols int) *rowWindow {
+	window := &rowWindow{
+		rows:  make([]int, 0, k),
+		basis: make([]*mat.VecDense, k),
+		rhs:   make([]float64, k),
+	}
+	for j := range window.basis {
+		window.basis[j] = mat.NewVecDense(cols, nil)
+	}
+
+	return window
+}
+
+// project adds row to the window and moves x onto the intersection of the hyperplanes of its rows, scaled
+// by relaxation
+func (w *rowWindow) project(reader *rowReader, b, x *mat.VecDense, row int, relaxation float64) {
+	if len(w.rows) < cap(w.rows) {
+		w.rows = append(w.rows, row)
+	} else {
+		w.rows[w.next] = row
+		w.next = (w.next + 1) % len(w.rows)
+	}
+
+	// Gram-Schmidt on the rows of the window, dropping those that depend on the previous ones
+	size := 0
+	for n := range w.rows {
+		j := w.rows[(w.next+n)%len(w.rows)]
+		q := w.basis[size]
+		q.CopyVec(reader.at(j))
+		norm := mat.Norm(q, 2)
+		rhs := b.AtVec(j)
+		for l := 0; l < size; l++ {
+			c := mat.Dot(w.basis[l], q)
+			q.AddScaledVec(q, -c, w.basis[l])
+			rhs -= c * w.rhs[l]
+		}
+
+		orthogonal := mat.Norm(q, 2)
+		if !(orthogonal > 1e-10*norm) {
+			continue
+		}
+		q.ScaleVec(1/orthogonal, q)
+		w.rhs[size] = rhs / orthogonal
+		size++
+	}
+
+	// The basis is orthonormal, so the projections onto its rows don't interfere
+	for l := 0; l < size; l++ {
+		x.AddScaledVec(x, relaxation*(w.rhs[l]-mat.Dot(w.basis[l], x)), w.basis[l])
+	}
+}
@@ -0,0 +1,162 @@
+package algorithms
+
+import (
+	"bytes"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentSolves is meant to be run with -race as well, every solve shares the matrix and the solver
+func TestConcurrentSolves(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	A, B, X := randomSystem(rnd, 40, 5)
+
+	solver, err := NewSolver(A)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	const solves = 50
+	solutions := make([]mat.VecDense, solves)
+	errs := make([]error, solves)
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(solves)
+	for k := 0; k < solves; k++ {
+		go func(k int) {
+			defer waitGroup.Done()
+
+			// Half of the solves are seeded, the others draw from the global source
+			var opts []Option
+			if k%2 == 0 {
+				opts = append(opts, WithSeed(uint64(k)))
+			}
+			b := mat.NewVecDense(40, mat.Col(nil, k%5, B))
+			solutions[k], _, errs[k] = solver.Solve(b, 100_000, 1e-20, true, opts...)
+		}(k)
+	}
+	waitGroup.Wait()
+
+	for k := 0; k < solves; k++ {
+		if errs[k] != nil {
+			t.Fatalf("solve %d: unexpected error %v", k, errs[k])
+		}
+		if want := X.ColView(k % 5); !mat.EqualApprox(&solutions[k], want, 1e-8) {
+			t.Fatalf("solve %d: x = %v, want %v", k, solutions[k].RawVector().Data, mat.Col(nil, k%5, X))
+		}
+	}
+}
+
+func TestContinue(t *testing.T) {
+	A, b := inconsistentSystem()
+	solver, err := NewSolver(A)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	once, onceErrs, err := solver.Solve(b, 1500, 0, true, WithSeed(3))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	state, err := solver.Start(b, 0, true, WithSeed(3))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, _, err := solver.Continue(state, 1000); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resumed, resumedErrs, err := solver.Continue(state, 500)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if !mat.Equal(&once, &resumed) {
+		t.Errorf("x = %v after 1000+500 iterations, want %v", resumed.RawVector().Data, once.RawVector().Data)
+	}
+	if state.Iterations() != 1500 || len(resumedErrs) != len(onceErrs) {
+		t.Errorf("%d iterations and %d errors, want 1500 of each", state.Iterations(), len(resumedErrs))
+	}
+	for i := range onceErrs {
+		if onceErrs[i] != resumedErrs[i] {
+			t.Fatalf("error %d = %g, want %g", i, resumedErrs[i], onceErrs[i])
+		}
+	}
+}
+
+func TestVerboseAdaptiveSampling(t *testing.T) {
+	A, b := inconsistentSystem()
+	var logged bytes.Buffer
+
+	_, _, err := RandomizedKaczmarz(A, b, 200, 0, false, WithSeed(1), WithAdaptiveSampling(10),
+		WithVerbose(log.New(&logged, "", 0)))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The draws of the adaptive samplers are counted with those of the first one
+	if !strings.Contains(logged.String(), "drew 200 rows of A") || strings.Count(logged.String(), "drew ") != 1 {
+		t.Errorf("the log doesn't count the 200 draws once:\n%s", logged.String())
+	}
+}
+
+func TestAdaptiveSampling(t *testing.T) {
+	// Over a few systems, so that the comparison doesn't hinge on a lucky draw
+	static, adaptive := 0, 0
+	for seed := uint64(1); seed <= 3; seed++ {
+		A, B, X := randomSystem(rand.New(rand.NewSource(seed)), 200, 10)
+		b := mat.NewVecDense(200, mat.Col(nil, 0, B))
+
+		_, errs, err := RandomizedKaczmarz(A, b, 100_000, 1e-20, true, WithSeed(1))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		static += len(errs)
+
+		x, errs, err := RandomizedKaczmarz(A, b, 100_000, 1e-20, true, WithSeed(1), WithAdaptiveSampling(10))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		checkSolution(t, "adaptive", x.RawVector().Data, mat.Col(nil, 0, X))
+		adaptive += len(errs)
+	}
+
+	if adaptive >= static {
+		t.Errorf("%d iterations to the tolerance with adaptive sampling, %d with static sampling", adaptive, static)
+	}
+}
+
+// BenchmarkGramCache solves a 200x200 system for several right-hand sides with the same Solver, keeping the errors,
+// with the Gram matrix cached by NewSolver and with the residual computed from scratch at every iteration
+func BenchmarkGramCache(bench *testing.B) {
+	A, B, _ := randomSystem(rand.New(rand.NewSource(1)), 200, 200)
+
+	for _, benchmark := range []struct {
+		name string
+		opts []Option
+	}{
+		{"cached", []Option{WithGramCache(200)}},
+		{"recomputed", nil},
+	} {
+		bench.Run(benchmark.name, func(bench *testing.B) {
+			solver, err := NewSolver(A, benchmark.opts...)
+			if err != nil {
+				bench.Fatal(err)
+			}
+
+			bench.ReportAllocs()
+			bench.ResetTimer()
+			for n := 0; n < bench.N; n++ {
+				for k := 0; k < 10; k++ {
+					b := mat.NewVecDense(200, mat.Col(nil, k, B))
+					if _, _, err := solver.Solve(b, 2000, 0, true, WithSeed(uint64(k))); err != nil {
+						bench.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
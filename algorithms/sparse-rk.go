@@ -0,0 +1,132 @@
+package algorithms
+
+import (
+	"github.com/alexandru-balan/go-rk-rk/sparse"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RandomizedKaczmarzSparse is RandomizedKaczmarz for a system A*x=b stored as a sparse CSR matrix.
+//
+// Row norms, projections and residuals only touch the non-zero values of A, so a single iteration costs
+// O(nnz(row)) and A is never densified.
+//
+// Parameters:
+// A is a sparse.CSR matrix representing the system.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithRowProbabilities.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrDimensionMismatch is returned if b doesn't have as many rows as A, an ErrInvalidOption if the row
+// probabilities or the row scaling don't fit A and an ErrZeroMatrix if A is zero.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+func RandomizedKaczmarzSparse(A *sparse.CSR, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	start, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	x := start.RawVector().Data
+
+	track, err := newTracker(o, tolerance, keepErrors, start, func() float64 {
+		residual := 0.0
+		for row := 0; row < rowsA; row++ {
+			ind, data := A.RowNonZeros(row)
+			diff := sparseDot(ind, data, x) - b.AtVec(row)
+			residual += diff * diff
+		}
+		return residual
+	})
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability and the norm of each row of A from its non-zero values
+	probsA := make([]float64, rowsA)
+	normsA := make([]float64, rowsA)
+	frobeniusA := fillProbabilities(probsA, normsA, func(i int) float64 {
+		_, data := A.RowNonZeros(i)
+		return blas64.Nrm2(blas64.Vector{N: len(data), Inc: 1, Data: data})
+	})
+	if err := checkNonZero(frobeniusA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 2.
+	// Applying the row probabilities given as options
+
+	if err := o.rowSampling(probsA, normsA); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	samplerA, err := o.newSampler("row", "A", probsA, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	if o.nonnegative {
+		clampNegative(x)
+	}
+
+	// STEP 3.
+	// Projecting x onto the hyperplane of a randomly chosen row, touching only its non-zero values
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+
+		ind, data := A.RowNonZeros(randA)
+
+		step := o.relaxation * ((b.AtVec(randA) - sparseDot(ind, data, x)) / normsA[randA]) / normsA[randA]
+		for k, j := range ind {
+			x[j] += step * data[k]
+			if o.nonnegative && x[j] < 0 {
+				x[j] = 0
+			}
+		}
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, start, b)
+
+	return *start, track.errors, track.err
+}
+
+// sparseDot returns the dot product between a sparse row, given by its column indices and values, and x
+func sparseDot(ind []int, data, x []float64) float64 {
+	dot := 0.0
+	for k, j := range ind {
+		dot += data[k] * x[j]
+	}
+
+	return dot
+}
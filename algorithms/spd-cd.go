@@ -0,0 +1,119 @@
+package algorithms
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// RandomizedCoordinateDescentSPD returns the solution of a symmetric positive definite system A*x=b using the
+// randomized coordinate descent algorithm of Leventhal and Lewis.
+//
+// Kaczmarz on a symmetric positive definite system converges with the square of its condition number, while
+// this method works on A itself: at every iteration an entry i of x is chosen with probability proportional to
+// A[i][i] and moved so that the i-th equation holds, which minimizes the energy norm of the error along e_i.
+//
+// Parameters:
+// A is a mat.Matrix representing the system, usually a mat.SymDense. Any other matrix must be symmetric up to
+// rounding errors.
+// b is a mat.VecDense vector that represents the expected output for the system.
+// iterations is an int representing how many times MAX is the algorithm allowed to run.
+// tolerance is a float64 that represents the maximal error allowed.
+// keepErrors is a boolean that specifies whether you want the function to retain the error at each iteration.
+// opts are optional settings such as WithSeed, WithRelaxation or WithInitialGuess.
+//
+// Returns the vector x that solves A*x=b and a []float64 array containing the errors at each iteration.
+// An ErrNotSymmetric is returned if A isn't square and symmetric or has a diagonal entry that isn't positive, and
+// an ErrDimensionMismatch if b doesn't have as many rows as A.
+//
+// Notes:
+// The iterations, tolerance and errors behave as in RandomizedKaczmarz.
+// The symmetry of A is only checked entry by entry when A isn't a mat.Symmetric. A positive diagonal doesn't
+// prove that A is positive definite; on an indefinite A the iteration may not converge.
+// Since the row and the column of an entry are the same, the residual b-A*x is updated with a single row of A at
+// every iteration and no transpose of A is ever built. It is computed again from A and x after every rows
+// iterations so that the rounding errors of the updates don't pile up and skew the tolerance check.
+func RandomizedCoordinateDescentSPD(A mat.Matrix, b *mat.VecDense, iterations int, tolerance float64, keepErrors bool, opts ...Option) (mat.VecDense, []float64, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if iterations < 0 {
+		iterations = 100_000
+	}
+
+	o := newOptions(opts)
+	src := o.source()
+
+	if err := o.checkFinite(A, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := checkSymmetric(A); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	rowsA, colsA := A.Dims()
+	iterations = o.epochIterations(iterations, rowsA)
+	if err := checkLength(b, "b", rowsA, "A"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	if err := o.checkFinite(b, "b"); err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// Reader of the rows of A, which reuses the same vector for every row
+	readerA := newRowReader(A)
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", colsA, "the columns of A")
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	residual := mat.NewVecDense(rowsA, nil)
+	resetResidual := func() {
+		residual.MulVec(A, x)
+		residual.SubVec(b, residual)
+	}
+	resetResidual()
+
+	track, err := newTracker(o, tolerance, keepErrors, x, func() float64 {
+		return EuclideanNormSquared(residual)
+	})
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+
+	// STEP 1.
+	// Computing the probability of each entry from the diagonal of A
+	diagonal := make([]float64, rowsA)
+	for i := range diagonal {
+		diagonal[i] = A.At(i, i)
+	}
+
+	samplerA, err := o.newSampler("entry", "x", diagonal, src)
+	if err != nil {
+		return mat.VecDense{}, nil, err
+	}
+	defer o.logSampling()
+
+	// STEP 2.
+	// Solving the equation of a random entry of x and updating the residual with its row
+	for i := 0; i < iterations; i++ {
+		randA := samplerA.Next()
+		chosenA := readerA.at(randA)
+
+		step := o.relaxation * residual.AtVec(randA) / diagonal[randA]
+
+		x.SetVec(randA, x.AtVec(randA)+step)
+		if (i+1)%rowsA == 0 {
+			resetResidual()
+		} else {
+			residual.AddScaledVec(residual, -step, chosenA)
+		}
+
+		if track.record(i) {
+			break
+		}
+	}
+
+	o.reportResidual(A, x, b)
+
+	return *x, track.errors, track.err
+}
@@ -0,0 +1,61 @@
+package algorithms
+
+import (
+	"errors"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"testing"
+)
+
+func TestRandomizedCoordinateDescentSPD(t *testing.T) {
+	// A = M^T*M + I is symmetric positive definite
+	M, _, X := randomSystem(rand.New(rand.NewSource(1)), 30, 8)
+	A := mat.NewSymDense(8, nil)
+	A.SymOuterK(1, M.T())
+	for i := 0; i < 8; i++ {
+		A.SetSym(i, i, A.At(i, i)+1)
+	}
+	want := mat.NewVecDense(8, mat.Col(nil, 0, X))
+	b := mat.NewVecDense(8, nil)
+	b.MulVec(A, want)
+
+	x, errs, err := RandomizedCoordinateDescentSPD(A, b, 100_000, 1e-20, true, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "mat.SymDense", x.RawVector().Data, want.RawVector().Data)
+
+	// The tracked residual, updated at every iteration, still matches b-A*x at the end
+	residual := mat.NewVecDense(8, nil)
+	residual.MulVec(A, &x)
+	residual.SubVec(b, residual)
+	if last := errs[len(errs)-1]; last > 1e-20 || EuclideanNormSquared(residual) > 1e-18 {
+		t.Errorf("last error %g but |b-A*x|^2 = %g", last, EuclideanNormSquared(residual))
+	}
+
+	// The same matrix stored densely is checked entry by entry and accepted
+	x, _, err = RandomizedCoordinateDescentSPD(mat.DenseCopyOf(A), b, 100_000, 1e-20, true, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "mat.Dense", x.RawVector().Data, want.RawVector().Data)
+}
+
+func TestRandomizedCoordinateDescentSPDNotSymmetric(t *testing.T) {
+	b := mat.NewVecDense(2, []float64{1, 1})
+
+	matrices := []struct {
+		name string
+		A    mat.Matrix
+	}{
+		{"asymmetric", mat.NewDense(2, 2, []float64{2, 1, 0, 2})},
+		{"non-positive diagonal", mat.NewSymDense(2, []float64{2, 1, 1, 0})},
+		{"not square", mat.NewDense(2, 3, []float64{1, 0, 0, 0, 1, 0})},
+	}
+
+	for _, matrix := range matrices {
+		if _, _, err := RandomizedCoordinateDescentSPD(matrix.A, b, 10, 0, false); !errors.Is(err, ErrNotSymmetric) {
+			t.Errorf("%s: got error %v, want %v", matrix.name, err, ErrNotSymmetric)
+		}
+	}
+}
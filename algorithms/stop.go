@@ -0,0 +1,56 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+)
+
+// StopReason tells why a solve stopped, see WithStopReason
+type StopReason int
+
+const (
+	// StopMaxIterations means the solver ran all the iterations it was allowed to
+	StopMaxIterations StopReason = iota
+	// StopTolerance means the error dropped to the tolerance, or the solution was exact
+	StopTolerance
+	// StopStagnation means the error stopped decreasing, see WithStagnation
+	StopStagnation
+	// StopTimeout means the time budget given to WithTimeout ran out, or the deadline of the context of a
+	// cancellable solve passed
+	StopTimeout
+	// StopCancelled means the context of a cancellable solve was cancelled or the channel of WithStopChannel was
+	// closed
+	StopCancelled
+	// StopNotFinite means the solution held a NaN or an infinity, see WithFiniteCheck
+	StopNotFinite
+)
+
+// String returns a short description of the reason
+func (r StopReason) String() string {
+	switch r {
+	case StopMaxIterations:
+		return "max iterations"
+	case StopTolerance:
+		return "tolerance"
+	case StopStagnation:
+		return "stagnation"
+	case StopTimeout:
+		return "timeout"
+	case StopCancelled:
+		return "cancelled"
+	case StopNotFinite:
+		return "not finite"
+	}
+
+	return "unknown"
+}
+
+// contextReason returns why a solve stopped when its context is done: StopTimeout if the deadline of ctx passed,
+// StopCancelled if ctx was cancelled
+func contextReason(ctx context.Context) StopReason {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return StopTimeout
+	}
+
+	return StopCancelled
+}
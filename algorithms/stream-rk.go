@@ -0,0 +1,175 @@
+package algorithms
+
+import (
+	"container/heap"
+	"fmt"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// StreamRow is a row of a system A*x=b streamed to StreamingKaczmarz: a row of A and the matching entry of b
+type StreamRow struct {
+	Row []float64
+	B   float64
+}
+
+// StreamingKaczmarz returns an approximate solution of a consistent system A*x=b whose rows arrive one at a time
+// on a channel, for systems too large to hold in memory.
+//
+// The solver keeps a buffer of at most bufferSize rows, chosen among all the rows streamed so far by weighted
+// reservoir sampling (Efraimidis and Spirakis) with weights equal to their squared norms. After every streamed row
+// x is projected stepsPerRow times onto rows drawn uniformly from the buffer, which approximates the norm-weighted
+// sampling of RandomizedKaczmarz without knowing the whole matrix.
+//
+// Parameters:
+// rows is a channel of StreamRow, read until it is closed. The solver keeps the Row slices it buffers, so they
+// must not be modified after being sent.
+// cols is an int giving the number of columns of A, the length of every row.
+// bufferSize is an int giving how many rows are kept in memory at most. Values below 1 are treated as 1.
+// stepsPerRow is an int giving how many projections follow every streamed row. Values below 1 are treated as 1.
+// opts are optional settings such as WithSeed, WithRelaxation or WithInitialGuess.
+//
+// Returns the vector x after the last streamed row.
+// An ErrDimensionMismatch is returned if cols isn't positive or a row doesn't have cols entries and an
+// ErrNonFiniteInput if a row holds a NaN or an infinity. The rest of the stream is then read and dropped in the
+// background, so the sender isn't left blocked, but it must still close the channel.
+//
+// Notes:
+// This is an approximation: the buffer is a norm-weighted sample of the rows streamed so far, but x only ever sees
+// the rows that were in the buffer at some point and each row is only projected on while it stays there, so early
+// rows get more projections than late ones of the same norm. On a consistent system x still moves towards the
+// solution at every step, but the convergence rate of RandomizedKaczmarz only holds once the buffer is a good
+// sample of the whole matrix, so a buffer much larger than cols and several steps per row give the best results.
+// Inconsistent streams leave x fluctuating around the least-squares solution of the buffered rows.
+// There is no residual to track without the whole matrix, so no errors are returned and tolerance based options
+// such as WithHistory don't apply.
+func StreamingKaczmarz(rows <-chan StreamRow, cols, bufferSize, stepsPerRow int, opts ...Option) (mat.VecDense, error) {
+
+	// STEP 0.
+	// Initialization of variables
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	if stepsPerRow < 1 {
+		stepsPerRow = 1
+	}
+
+	if cols <= 0 {
+		go drainRows(rows)
+		return mat.VecDense{}, fmt.Errorf("%w: the rows must have at least one column, got %d", ErrDimensionMismatch, cols)
+	}
+
+	o := newOptions(opts)
+	var rnd *rand.Rand
+	if src := o.source(); src != nil {
+		rnd = rand.New(src)
+	}
+	uniform := func() float64 {
+		if rnd == nil {
+			return rand.Float64()
+		}
+		return rnd.Float64()
+	}
+	intn := func(n int) int {
+		if rnd == nil {
+			return rand.Intn(n)
+		}
+		return rnd.Intn(n)
+	}
+
+	x, err := startingPoint(o.initialGuess, "the initial guess", cols, "the columns of A")
+	if err != nil {
+		go drainRows(rows)
+		return mat.VecDense{}, err
+	}
+
+	buffer := make(reservoir, 0, bufferSize)
+	streamed := 0
+
+	// STEP 1.
+	// Buffering every streamed row by its key and projecting x onto buffered rows
+	for row := range rows {
+		if len(row.Row) != cols {
+			go drainRows(rows)
+			return *x, fmt.Errorf("%w: row %d has %d columns, expected %d", ErrDimensionMismatch, streamed, len(row.Row), cols)
+		}
+		if !o.skipInputCheck {
+			if math.IsNaN(row.B) || math.IsInf(row.B, 0) {
+				go drainRows(rows)
+				return *x, fmt.Errorf("%w: entry %d of b is %g", ErrNonFiniteInput, streamed, row.B)
+			}
+		}
+
+		if !o.skipInputCheck {
+			for j, value := range row.Row {
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					go drainRows(rows)
+					return *x, fmt.Errorf("%w: entry (%d, %d) of A is %g", ErrNonFiniteInput, streamed, j, value)
+				}
+			}
+		}
+		norm := blas64.Nrm2(blas64.Vector{N: len(row.Row), Inc: 1, Data: row.Row})
+		streamed++
+
+		// Zero rows have nothing to project on and are never buffered
+		if norm > 0 {
+			// The key 2*log(||a||)-log(-log(u)) is a monotone transform of u^(1/||a||^2), so the rows with the
+			// largest keys are a weighted sample, and unlike log(u)/||a||^2 it never overflows nor underflows
+			key := 2*math.Log(norm) - math.Log(-math.Log(uniform()))
+			entry := streamedRow{row: mat.NewVecDense(cols, row.Row), b: row.B, norm: norm, key: key}
+			switch {
+			case len(buffer) < bufferSize:
+				heap.Push(&buffer, entry)
+			case entry.key > buffer[0].key:
+				buffer[0] = entry
+				heap.Fix(&buffer, 0)
+			}
+		}
+
+		if len(buffer) == 0 {
+			continue
+		}
+
+		for k := 0; k < stepsPerRow; k++ {
+			chosen := buffer[intn(len(buffer))]
+
+			x.AddScaledVec(
+				x,
+				o.relaxation*((chosen.b-mat.Dot(chosen.row, x))/chosen.norm)/chosen.norm,
+				chosen.row)
+		}
+	}
+
+	return *x, nil
+}
+
+// drainRows reads and drops the rows left in the stream until it is closed, so that the sender doesn't block
+// forever once StreamingKaczmarz stops reading
+func drainRows(rows <-chan StreamRow) {
+	for range rows {
+	}
+}
+
+// streamedRow is a row buffered by StreamingKaczmarz with its entry of b, its norm and its sampling key
+type streamedRow struct {
+	row  *mat.VecDense
+	b    float64
+	norm float64
+	key  float64
+}
+
+// reservoir is a min-heap of buffered rows by key, so the row to evict is always at the top
+type reservoir []streamedRow
+
+func (r reservoir) Len() int            { return len(r) }
+func (r reservoir) Less(i, j int) bool  { return r[i].key < r[j].key }
+func (r reservoir) Swap(i, j int)       { r[i], r[j] = r[j], r[i] }
+func (r *reservoir) Push(x interface{}) { *r = append(*r, x.(streamedRow)) }
+func (r *reservoir) Pop() interface{} {
+	old := *r
+	last := old[len(old)-1]
+	*r = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,65 @@
+package algorithms
+
+import (
+	"errors"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"testing"
+	"time"
+)
+
+// streamSystem sends the rows of A and the entries of b on a channel, closing it and done once all are sent
+func streamSystem(A *mat.Dense, b *mat.VecDense) (<-chan StreamRow, <-chan struct{}) {
+	rows, done := make(chan StreamRow), make(chan struct{})
+	go func() {
+		rowsA, _ := A.Dims()
+		for i := 0; i < rowsA; i++ {
+			rows <- StreamRow{Row: mat.Row(nil, i, A), B: b.AtVec(i)}
+		}
+		close(rows)
+		close(done)
+	}()
+
+	return rows, done
+}
+
+func TestStreamingKaczmarz(t *testing.T) {
+	A, B, X := randomSystem(rand.New(rand.NewSource(1)), 300, 5)
+	b := mat.NewVecDense(300, mat.Col(nil, 0, B))
+
+	rows, _ := streamSystem(A, b)
+	x, err := StreamingKaczmarz(rows, 5, 100, 20, WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	checkSolution(t, "x", x.RawVector().Data, mat.Col(nil, 0, X))
+}
+
+func TestStreamingKaczmarzRowLength(t *testing.T) {
+	A := mat.NewDense(50, 3, nil)
+	for i := 0; i < 50; i++ {
+		A.Set(i, i%3, 1)
+	}
+	b := mat.NewVecDense(50, nil)
+
+	// The rows have 3 columns, not 4, and the sender must still be able to send all of them
+	rows, done := streamSystem(A, b)
+	if _, err := StreamingKaczmarz(rows, 4, 10, 1); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("got error %v, want %v", err, ErrDimensionMismatch)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sender is still blocked after the solver returned")
+	}
+
+	rows, done = streamSystem(A, b)
+	if _, err := StreamingKaczmarz(rows, 0, 10, 1); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("cols = 0: got error %v, want %v", err, ErrDimensionMismatch)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sender is still blocked after the solver returned")
+	}
+}
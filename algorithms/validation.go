@@ -0,0 +1,270 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/cmplx"
+	"reflect"
+)
+
+// ErrDimensionMismatch is returned when the dimensions of the matrices and vectors of a system don't agree
+var ErrDimensionMismatch = errors.New("dimension mismatch")
+
+// ErrInvalidOption is returned when an option doesn't fit the system it is used on
+var ErrInvalidOption = errors.New("invalid option")
+
+// ErrNotFinite is returned when the iteration diverged or hit a numerical problem and the solution holds
+// a NaN or an infinity, see WithFiniteCheck
+var ErrNotFinite = errors.New("non-finite solution")
+
+// ErrNotSymmetric is returned when a solver for symmetric positive definite systems is given a matrix that isn't
+// symmetric or has a diagonal entry that isn't positive
+var ErrNotSymmetric = errors.New("not a symmetric positive definite matrix")
+
+// ErrNonFiniteInput is returned when a matrix or a vector given to a solver holds a NaN or an infinity,
+// see WithInputCheck
+var ErrNonFiniteInput = errors.New("non-finite input")
+
+// ErrZeroMatrix is returned when a matrix has no non-zero entry, so there is no row to project on
+var ErrZeroMatrix = errors.New("zero matrix")
+
+// checkLength returns an ErrDimensionMismatch if vector doesn't have the expected number of rows of matrix
+func checkLength(vector *mat.VecDense, vectorName string, rows int, matrixName string) error {
+	if vector.Len() != rows {
+		return fmt.Errorf("%w: %s has %d rows, expected %d to match %s", ErrDimensionMismatch, vectorName, vector.Len(), rows, matrixName)
+	}
+
+	return nil
+}
+
+// isNil reports whether matrix is nil or holds a nil pointer, like a (*mat.Dense)(nil), whose methods would panic
+func isNil(matrix mat.Matrix) bool {
+	if matrix == nil {
+		return true
+	}
+	value := reflect.ValueOf(matrix)
+
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// checkRows returns an ErrDimensionMismatch if matrix doesn't have the expected number of rows of another matrix
+func checkRows(matrix mat.Matrix, name string, rows int, otherName string) error {
+	if r, _ := matrix.Dims(); r != rows {
+		return fmt.Errorf("%w: %s has %d rows, expected %d to match %s", ErrDimensionMismatch, name, r, rows, otherName)
+	}
+
+	return nil
+}
+
+// checkCoupling returns an ErrDimensionMismatch if V*b=x can't be coupled with U*x=y.
+//
+// The solution x of U*x=y has one entry per column of U and it is the right-hand side of V*b=x, so V must have
+// one row per column of U. Otherwise the row of V chosen at an iteration could index past the end of x.
+func checkCoupling(U, V mat.Matrix) error {
+	_, colsU := U.Dims()
+	rowsV, _ := V.Dims()
+
+	if rowsV != colsU {
+		return fmt.Errorf("%w: V has %d rows, expected %d to match the columns of U", ErrDimensionMismatch, rowsV, colsU)
+	}
+
+	return nil
+}
+
+// checkNonZero returns an ErrZeroMatrix if the frobenius norm of the matrix is zero
+func checkNonZero(frobenius float64, matrixName string) error {
+	if frobenius == 0 {
+		return fmt.Errorf("%w: every row of %s is zero", ErrZeroMatrix, matrixName)
+	}
+
+	return nil
+}
+
+// checkPartition returns an ErrInvalidOption if a block is empty or holds a row outside [0, rows)
+func checkPartition(blocks [][]int, rows int) error {
+	for k, block := range blocks {
+		if len(block) == 0 {
+			return fmt.Errorf("%w: block %d of the partition is empty", ErrInvalidOption, k)
+		}
+		for _, row := range block {
+			if row < 0 || row >= rows {
+				return fmt.Errorf("%w: block %d of the partition holds row %d, but the matrix has %d rows", ErrInvalidOption, k, row, rows)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkWeights returns an ErrInvalidOption if weights doesn't hold one non-negative entry per row
+func checkWeights(weights []float64, rows int) error {
+	if len(weights) != rows {
+		return fmt.Errorf("%w: %d averaging weights given, expected one for each of the %d rows", ErrInvalidOption, len(weights), rows)
+	}
+	for row, weight := range weights {
+		if !(weight >= 0) {
+			return fmt.Errorf("%w: the averaging weight of row %d is %g", ErrInvalidOption, row, weight)
+		}
+	}
+
+	return nil
+}
+
+// checkProbabilities returns an ErrInvalidOption if probabilities doesn't hold one non-negative entry per row
+func checkProbabilities(probabilities []float64, rows int) error {
+	if len(probabilities) != rows {
+		return fmt.Errorf("%w: %d row probabilities given, expected one for each of the %d rows", ErrInvalidOption, len(probabilities), rows)
+	}
+	for row, prob := range probabilities {
+		if !(prob >= 0) {
+			return fmt.Errorf("%w: the probability of row %d is %g", ErrInvalidOption, row, prob)
+		}
+	}
+
+	return nil
+}
+
+// checkSamplingWeights returns an error if no sampler can be built for the weights of the rows, columns or
+// blocks (the kind) of the matrix called matrix: an ErrNonFiniteInput if a weight is NaN or infinite, which only
+// happens when WithInputCheck disabled the check of a non-finite matrix, an ErrInvalidOption if a weight is
+// negative and an ErrZeroMatrix if no weight is positive.
+func checkSamplingWeights(weights []float64, kind, matrix string) error {
+	sum := 0.0
+	for i, weight := range weights {
+		switch {
+		case math.IsNaN(weight) || math.IsInf(weight, 0):
+			return fmt.Errorf("%w: the sampling weight of %s %d of %s is %g", ErrNonFiniteInput, kind, i, matrix, weight)
+		case weight < 0:
+			return fmt.Errorf("%w: the sampling weight of %s %d of %s is %g", ErrInvalidOption, kind, i, matrix, weight)
+		}
+		sum += weight
+	}
+	if !(sum > 0) {
+		return fmt.Errorf("%w: no %s of %s can be drawn", ErrZeroMatrix, kind, matrix)
+	}
+
+	return nil
+}
+
+// checkScaling returns an ErrInvalidOption if scaling doesn't hold one finite entry per row
+func checkScaling(scaling []float64, rows int) error {
+	if len(scaling) != rows {
+		return fmt.Errorf("%w: %d row scalings given, expected one for each of the %d rows", ErrInvalidOption, len(scaling), rows)
+	}
+	for row, d := range scaling {
+		if math.IsNaN(d) || math.IsInf(d, 0) {
+			return fmt.Errorf("%w: the scaling of row %d is %g", ErrInvalidOption, row, d)
+		}
+	}
+
+	return nil
+}
+
+// checkSymmetric returns an ErrNotSymmetric if A isn't square, A[i][j] and A[j][i] differ by more than a relative
+// tolerance or a diagonal entry of A isn't positive. Matrices implementing mat.Symmetric are symmetric by
+// construction and only their diagonal is checked.
+func checkSymmetric(A mat.Matrix) error {
+	rows, cols := A.Dims()
+	if rows != cols {
+		return fmt.Errorf("%w: A is %d*%d, expected a square matrix", ErrNotSymmetric, rows, cols)
+	}
+
+	if _, ok := A.(mat.Symmetric); !ok {
+		largest := mat.Norm(A, math.Inf(1))
+		for i := 0; i < rows; i++ {
+			for j := i + 1; j < cols; j++ {
+				if math.Abs(A.At(i, j)-A.At(j, i)) > 1e-10*largest {
+					return fmt.Errorf("%w: entries (%d, %d) and (%d, %d) of A differ", ErrNotSymmetric, i, j, j, i)
+				}
+			}
+		}
+	}
+
+	for i := 0; i < rows; i++ {
+		if !(A.At(i, i) > 0) {
+			return fmt.Errorf("%w: diagonal entry %d of A is %g", ErrNotSymmetric, i, A.At(i, i))
+		}
+	}
+
+	return nil
+}
+
+// rowNonZeroer is a sparse matrix that lists the non-zero values of its rows, like sparse.CSR
+type rowNonZeroer interface {
+	RowNonZeros(i int) (ind []int, data []float64)
+}
+
+// checkFinite returns an ErrNonFiniteInput pointing at the first NaN or infinity of matrix, unless the check
+// was turned off by WithInputCheck. Vectors are checked as matrices with a single column.
+func (o *options) checkFinite(matrix mat.Matrix, name string) error {
+	if o.skipInputCheck {
+		return nil
+	}
+
+	rows, cols := matrix.Dims()
+	sparseRows, isSparse := matrix.(rowNonZeroer)
+	buf := make([]float64, cols)
+	for i := 0; i < rows; i++ {
+		var ind []int
+		var data []float64
+		switch {
+		case isSparse:
+			ind, data = sparseRows.RowNonZeros(i)
+		case cols == 1:
+			buf[0] = matrix.At(i, 0)
+			data = buf
+		default:
+			data = rowOf(matrix, i, buf).RawVector().Data
+		}
+
+		for k, value := range data {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				j := k
+				if isSparse {
+					j = ind[k]
+				}
+				return fmt.Errorf("%w: entry (%d, %d) of %s is %g", ErrNonFiniteInput, i, j, name, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFiniteCmplx is checkFinite for the rows*cols complex values of data, stored row after row with the
+// given stride
+func (o *options) checkFiniteCmplx(data []complex128, rows, cols, stride int, name string) error {
+	if o.skipInputCheck {
+		return nil
+	}
+
+	for i := 0; i < rows; i++ {
+		for j, value := range data[i*stride : i*stride+cols] {
+			if cmplx.IsNaN(value) || cmplx.IsInf(value) {
+				return fmt.Errorf("%w: entry (%d, %d) of %s is %g", ErrNonFiniteInput, i, j, name, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFinite32 returns an ErrNonFiniteInput pointing at the first NaN or infinity of the float32 matrix stored
+// in data, unless the check was turned off by WithInputCheck
+func (o *options) checkFinite32(data []float32, rows, cols, stride int, name string) error {
+	if o.skipInputCheck {
+		return nil
+	}
+
+	for i := 0; i < rows; i++ {
+		for j, value := range data[i*stride : i*stride+cols] {
+			if v := float64(value); math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("%w: entry (%d, %d) of %s is %g", ErrNonFiniteInput, i, j, name, value)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package algorithms
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNonFiniteInput(t *testing.T) {
+	A, b, _ := smallSystem()
+
+	nanA := mat.DenseCopyOf(A)
+	nanA.Set(2, 1, math.NaN())
+	infB := mat.VecDenseCopyOf(b)
+	infB.SetVec(3, math.Inf(-1))
+
+	inputs := []struct {
+		name    string
+		A       mat.Matrix
+		b       *mat.VecDense
+		message string
+	}{
+		{"NaN in A", nanA, b, "entry (2, 1) of A is NaN"},
+		{"infinity in b", A, infB, "entry (3, 0) of b is -Inf"},
+	}
+
+	for _, input := range inputs {
+		_, _, err := RandomizedKaczmarz(input.A, input.b, 10, 0, false)
+		if !errors.Is(err, ErrNonFiniteInput) {
+			t.Errorf("%s: got error %v, want %v", input.name, err, ErrNonFiniteInput)
+		} else if !strings.Contains(err.Error(), input.message) {
+			t.Errorf("%s: error %q doesn't say %q", input.name, err, input.message)
+		}
+
+		_, _, err = RandomizedExtendedKaczmarz(input.A, input.b, 10, 0, false)
+		if !errors.Is(err, ErrNonFiniteInput) {
+			t.Errorf("%s: got error %v from RandomizedExtendedKaczmarz, want %v", input.name, err, ErrNonFiniteInput)
+		}
+	}
+
+	// Without the check the solve runs on the infinity instead of rejecting it
+	if _, _, err := RandomizedKaczmarz(A, infB, 10, 0, false, WithInputCheck(false), WithSeed(1)); err != nil {
+		t.Errorf("unexpected error %v without the input check", err)
+	}
+}
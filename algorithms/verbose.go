@@ -0,0 +1,108 @@
+package algorithms
+
+import (
+	"golang.org/x/exp/rand"
+)
+
+// sampledRows holds the draws of a sampler, counted to be logged at the end of a solve, see WithVerbose
+type sampledRows struct {
+	kind   string
+	matrix string
+	counts []int
+	probs  []float64
+}
+
+// countingSampler counts how many times every index is drawn by the sampler it wraps
+type countingSampler struct {
+	indexSampler
+	counts []int
+}
+
+// Next returns the next index of the wrapped sampler and counts it
+func (s *countingSampler) Next() int {
+	i := s.indexSampler.Next()
+	s.counts[i]++
+
+	return i
+}
+
+// newSampler builds the sampler of a solve for the probabilities of the rows, columns or blocks (the kind)
+// of the matrix called matrix, following the strategy given to WithSamplingStrategy.
+//
+// If a logger was given to WithVerbose, the draws of the sampler are counted and reported by logSampling.
+// An error is returned if no sampler can be built for probs, see checkSamplingWeights.
+func (o *options) newSampler(kind, matrix string, probs []float64, src rand.Source) (indexSampler, error) {
+	if err := checkSamplingWeights(probs, kind, matrix); err != nil {
+		return nil, err
+	}
+
+	var sampler indexSampler
+	switch o.strategy {
+	case Cyclic:
+		sampler = newOrderedSampler(probs, false, src)
+	case RandomPermutation:
+		sampler = newOrderedSampler(probs, true, src)
+	default:
+		sampler = NewAliasSampler(probs, src)
+	}
+
+	return o.countDraws(kind, matrix, probs, sampler), nil
+}
+
+// countDraws wraps sampler so that its draws are counted and reported by logSampling if a logger was given to
+// WithVerbose, and returns it unchanged otherwise
+func (o *options) countDraws(kind, matrix string, probs []float64, sampler indexSampler) indexSampler {
+	if o.logger != nil {
+		counts := make([]int, len(probs))
+		o.sampled = append(o.sampled, sampledRows{kind: kind, matrix: matrix, counts: counts, probs: probs})
+		sampler = &countingSampler{indexSampler: sampler, counts: counts}
+	}
+
+	return sampler
+}
+
+// replaceSampler returns next in place of current. If the draws of current are counted, next is wrapped by it
+// instead, so that the draws of both keep adding up to the same counts.
+func replaceSampler(current, next indexSampler) indexSampler {
+	if counting, ok := current.(*countingSampler); ok {
+		counting.indexSampler = next
+		return counting
+	}
+
+	return next
+}
+
+// logSampling logs how many times every row, column or block of the counted samplers was drawn.
+//
+// A warning is logged for every one drawn more than ten times as often as it would be under uniform sampling,
+// which usually means that their norms are badly skewed, and for those that can never be drawn.
+func (o *options) logSampling() {
+	for _, s := range o.sampled {
+		counts := s.counts
+
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		o.logger.Printf("drew %d %ss of %s, draws per %s: %v", total, s.kind, s.matrix, s.kind, counts)
+
+		if total == 0 {
+			continue
+		}
+
+		uniform := float64(total) / float64(len(counts))
+		never := 0
+		for index, count := range counts {
+			if s.probs[index] == 0 {
+				never++
+			}
+			if float64(count) > 10*uniform {
+				o.logger.Printf("warning: %s %d of %s was drawn %d times, %.1f times its uniform share",
+					s.kind, index, s.matrix, count, float64(count)/uniform)
+			}
+		}
+		if never > 0 {
+			o.logger.Printf("warning: %d %ss of %s have a zero probability and are never drawn", never, s.kind, s.matrix)
+		}
+	}
+}
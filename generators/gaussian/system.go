@@ -0,0 +1,46 @@
+package gaussian
+
+import (
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// GenerateConsistentSystem returns a random rows*cols system A*x=b together with its solution xTrue.
+//
+// The entries of A and xTrue are drawn from the standard normal distribution and b is set to A*xTrue, so xTrue
+// solves the system exactly. The same seed always gives the same system.
+func GenerateConsistentSystem(rows, cols int, seed uint64) (A *mat.Dense, b, xTrue *mat.VecDense) {
+	return GenerateNoisySystem(rows, cols, 0, seed)
+}
+
+// GenerateNoisySystem returns a random rows*cols system A*x=b built like GenerateConsistentSystem,
+// except that normal noise with standard deviation noise is added to every entry of b.
+//
+// With a positive noise and more rows than columns the system is inconsistent, and xTrue is only close to its
+// least-squares solution. A noise of zero gives the consistent system of GenerateConsistentSystem.
+func GenerateNoisySystem(rows, cols int, noise float64, seed uint64) (A *mat.Dense, b, xTrue *mat.VecDense) {
+	distribution := distuv.Normal{Mu: 0, Sigma: 1, Src: rand.NewSource(seed)}
+
+	A = mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			A.Set(i, j, distribution.Rand())
+		}
+	}
+
+	xTrue = mat.NewVecDense(cols, nil)
+	for j := 0; j < cols; j++ {
+		xTrue.SetVec(j, distribution.Rand())
+	}
+
+	b = mat.NewVecDense(rows, nil)
+	b.MulVec(A, xTrue)
+	if noise != 0 {
+		for i := 0; i < rows; i++ {
+			b.SetVec(i, b.AtVec(i)+noise*distribution.Rand())
+		}
+	}
+
+	return A, b, xTrue
+}
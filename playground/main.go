@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"github.com/alexandru-balan/go-rk-rk/algorithms"
 	"github.com/alexandru-balan/go-rk-rk/generators/gaussian"
-	"github.com/alexandru-balan/go-rk-rk/utils"
+	"github.com/alexandru-balan/go-rk-rk/utils/plotutil"
 	"gonum.org/v1/gonum/mat"
+	"log"
 	"math"
 	"sync"
 	"time"
@@ -45,8 +46,16 @@ func main() {
 
 	tolerance := math.Pow(10, -4)
 	var errors []float64
-	*b, errors = algorithms.RkRek(U, V, y, 1_000_000, tolerance, true)
-	utils.Plot(errors, "./build/scatter.png")
+	var err error
+	*b, errors, err = algorithms.RkRek(U, V, y, 1_000_000, tolerance, true)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	err = plotutil.PlotConvergence(errors, "RK-REK", "./build/scatter.png")
+	if err != nil {
+		log.Panic(err)
+	}
 
 	fmt.Println(errors[0])
 	fmt.Println(errors[len(errors)-1])
@@ -0,0 +1,84 @@
+package sparse
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// CSR is a sparse matrix stored in compressed sparse row format.
+//
+// The non-zero values of row i are data[indptr[i]:indptr[i+1]] and their column indices are
+// ind[indptr[i]:indptr[i+1]]. Only the non-zero values are stored, so the matrix can be much larger
+// than what would fit in memory as a mat.Dense.
+type CSR struct {
+	rows, cols int
+	indptr     []int
+	ind        []int
+	data       []float64
+}
+
+// NewCSR creates a rows*cols CSR matrix from its row pointers, column indices and values.
+//
+// The slices are used as the backing storage of the matrix and are not copied.
+// NewCSR panics if the slices don't describe a valid rows*cols matrix.
+func NewCSR(rows, cols int, indptr, ind []int, data []float64) *CSR {
+	if rows <= 0 || cols <= 0 {
+		panic("sparse: zero or negative dimension")
+	}
+	if len(indptr) != rows+1 || indptr[0] != 0 || indptr[rows] != len(ind) || len(ind) != len(data) {
+		panic("sparse: malformed CSR storage")
+	}
+	for i := 0; i < rows; i++ {
+		if indptr[i] > indptr[i+1] {
+			panic("sparse: row pointers are not increasing")
+		}
+	}
+	for _, j := range ind {
+		if j < 0 || j >= cols {
+			panic("sparse: column index out of range")
+		}
+	}
+
+	return &CSR{rows: rows, cols: cols, indptr: indptr, ind: ind, data: data}
+}
+
+// Dims returns the number of rows and columns of the matrix
+func (m *CSR) Dims() (r, c int) {
+	return m.rows, m.cols
+}
+
+// At returns the element at row i and column j
+func (m *CSR) At(i, j int) float64 {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(mat.ErrIndexOutOfRange)
+	}
+
+	var value float64
+	for k := m.indptr[i]; k < m.indptr[i+1]; k++ {
+		if m.ind[k] == j {
+			value += m.data[k]
+		}
+	}
+
+	return value
+}
+
+// T returns the transpose of the matrix without copying it
+func (m *CSR) T() mat.Matrix {
+	return mat.Transpose{Matrix: m}
+}
+
+// NNZ returns the number of stored values
+func (m *CSR) NNZ() int {
+	return len(m.data)
+}
+
+// RowNonZeros returns the column indices and the values stored in row i.
+//
+// The returned slices view the storage of the matrix and must not be modified.
+func (m *CSR) RowNonZeros(i int) (ind []int, data []float64) {
+	if i < 0 || i >= m.rows {
+		panic(mat.ErrRowAccess)
+	}
+
+	return m.ind[m.indptr[i]:m.indptr[i+1]], m.data[m.indptr[i]:m.indptr[i+1]]
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"gonum.org/v1/gonum/stat"
+	"math"
+)
+
+// EstimateConvergenceRate estimates the linear convergence rate of the errors returned by a solver.
+//
+// It fits log(error) against the iteration with least squares, so that error ~ C*rate^iteration.
+// A rate below 1 means the errors decay geometrically and the smaller it is the faster the solver converges.
+// r2 is the coefficient of determination of the fit: the closer it is to 1, the closer the decay is to geometric.
+//
+// Errors that are zero, negative, NaN or infinite have no logarithm and are skipped. If fewer than two errors are
+// left both values are NaN.
+func EstimateConvergenceRate(errors []float64) (rate float64, r2 float64) {
+	var iterations, logs []float64
+	for i, value := range errors {
+		if value > 0 && !math.IsInf(value, 1) {
+			iterations = append(iterations, float64(i))
+			logs = append(logs, math.Log(value))
+		}
+	}
+
+	if len(logs) < 2 {
+		return math.NaN(), math.NaN()
+	}
+
+	alpha, beta := stat.LinearRegression(iterations, logs, nil, false)
+
+	return math.Exp(beta), stat.RSquared(iterations, logs, nil, alpha, beta)
+}
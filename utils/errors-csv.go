@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteErrorsCSV writes the errors returned by a solver to w as a two-column iteration,error CSV with a header.
+//
+// The errors are written with the shortest representation that reads back to the same float64.
+func WriteErrorsCSV(w io.Writer, errors []float64) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"iteration", "error"})
+	if err != nil {
+		return err
+	}
+
+	for i, value := range errors {
+		err = writer.Write([]string{strconv.Itoa(i), strconv.FormatFloat(value, 'g', -1, 64)})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SaveErrorsCSV writes the errors returned by a solver to the CSV file at path, see WriteErrorsCSV.
+//
+// The parent directories of path are created if they are missing. If path is empty nothing is written.
+func SaveErrorsCSV(errors []float64, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("creating csv directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating csv file: %w", err)
+	}
+
+	err = WriteErrorsCSV(file, errors)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("writing csv file: %w", err)
+	}
+
+	return file.Close()
+}
@@ -1,17 +1,55 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"gonum.org/v1/gonum/mat"
-	"log"
+	"math"
 )
 
-func SolveLeastSquares(X *mat.Dense, y *mat.VecDense) mat.VecDense {
+// SolveLeastSquares returns the least-squares solution of X*b=y computed from the thin SVD of X.
+//
+// If X doesn't have full rank the minimum-norm solution is returned, see LeastSquares.
+// Returns an error if X can't be factorized or y doesn't have as many rows as X.
+func SolveLeastSquares(X *mat.Dense, y *mat.VecDense) (mat.VecDense, error) {
+	Y := mat.NewDense(y.Len(), 1, nil)
+	Y.ColView(0).(*mat.VecDense).CopyVec(y)
+
+	b, err := LeastSquares(X, Y)
+	if err != nil {
+		return mat.VecDense{}, err
+	}
+
+	return *mat.VecDenseCopyOf(b.ColView(0)), nil
+}
+
+// LeastSquares returns the minimum-norm least-squares solution X of A*X=B, the reference the Kaczmarz solvers
+// converge to.
+//
+// Parameters:
+// A is a mat.Dense matrix representing the system.
+// B is a mat.Dense matrix whose columns are the right-hand sides, one column for a single system.
+//
+// Returns the solution, with a column for every column of B.
+// Returns an error if A can't be factorized or B doesn't have as many rows as A.
+//
+// Notes:
+// The solution is computed from the thin SVD of A. Singular values below max(rows, cols)*eps*sigma_max are
+// treated as zero, so a rank-deficient A gives the solution of minimum norm instead of one blown up by
+// rounding errors. This is the solution RandomizedKaczmarz converges to when started from zero.
+func LeastSquares(A, B *mat.Dense) (*mat.Dense, error) {
+	rowsA, colsA := A.Dims()
+	rowsB, colsB := B.Dims()
+	if rowsB != rowsA {
+		return nil, fmt.Errorf("B has %d rows, expected %d to match A", rowsB, rowsA)
+	}
+
 	// Create an SVD representation
 	svd := new(mat.SVD)
-	success := svd.Factorize(X, mat.SVDThin)
+	success := svd.Factorize(A, mat.SVDThin)
 
 	if !success {
-		log.Panic("Can't factorize the X into SVD")
+		return nil, errors.New("can't factorize A into SVD")
 	}
 
 	Right := new(mat.Dense)
@@ -20,15 +58,60 @@ func SolveLeastSquares(X *mat.Dense, y *mat.VecDense) mat.VecDense {
 	svd.VTo(Right)
 	svd.UTo(Left)
 
-	// Computing the least-squares solution
-	c := new(mat.VecDense)
-	c.MulVec(Left.T(), y)
+	// Keeping only the singular values that aren't rounding errors, which come first since they are sorted
+	rank := NumericalRank(Values, rowsA, colsA)
+
+	X := mat.NewDense(colsA, colsB, nil)
+	if rank == 0 {
+		return X, nil
+	}
+
+	// Computing the least-squares solution V*S^-1*U^T*B on the kept singular values
+	C := new(mat.Dense)
+	C.Mul(Left.Slice(0, rowsA, 0, rank).T(), B)
+	for i := 0; i < rank; i++ {
+		row := C.RawRowView(i)
+		for j := range row {
+			row[j] /= Values[i]
+		}
+	}
 
-	d := new(mat.VecDense)
-	d.DivElemVec(c.SliceVec(0, len(Values)), mat.NewVecDense(len(Values), Values))
+	X.Mul(Right.Slice(0, colsA, 0, rank), C)
 
-	b := new(mat.VecDense)
-	b.MulVec(Right, d)
+	return X, nil
+}
+
+// NumericalRank returns how many of the singular values of a rows*cols matrix aren't rounding errors.
+//
+// Parameters:
+// values are the singular values of the matrix in decreasing order, as returned by mat.SVD.Values.
+// rows and cols are the dimensions of the matrix.
+//
+// Notes:
+// Singular values below max(rows, cols)*eps*sigma_max are treated as zero. Since the values are sorted the kept
+// ones are the first NumericalRank(values, rows, cols). This is the cutoff LeastSquares and the pseudo-inverses
+// of the block Kaczmarz solvers use, so that they agree on the rank of a matrix.
+func NumericalRank(values []float64, rows, cols int) int {
+	if len(values) == 0 {
+		return 0
+	}
+
+	eps := math.Nextafter(1, 2) - 1
+	cutoff := float64(maxInt(rows, cols)) * eps * values[0]
+
+	rank := 0
+	for rank < len(values) && values[rank] > cutoff {
+		rank++
+	}
+
+	return rank
+}
+
+// maxInt returns the larger of a and b
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
 
-	return *b
+	return b
 }
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"testing"
+)
+
+func TestLeastSquaresRankDeficient(t *testing.T) {
+	// The third column is the sum of the first two, so A has rank 2 and its smallest singular value is rounding
+	A := mat.NewDense(4, 3, []float64{
+		1, 0, 1,
+		0, 1, 1,
+		1, 1, 2,
+		2, -1, 1,
+	})
+
+	svd := new(mat.SVD)
+	if !svd.Factorize(A, mat.SVDThin) {
+		t.Fatal("can't factorize A")
+	}
+	if rank := NumericalRank(svd.Values(nil), 4, 3); rank != 2 {
+		t.Errorf("NumericalRank = %d, want 2", rank)
+	}
+	if rank := NumericalRank(nil, 0, 0); rank != 0 {
+		t.Errorf("NumericalRank of no values = %d, want 0", rank)
+	}
+
+	// b is in the range of A, and the minimum-norm solution is orthogonal to the null space (1, 1, -1)
+	want := mat.NewVecDense(3, []float64{1, 0, 1})
+	b := mat.NewVecDense(4, nil)
+	b.MulVec(A, want)
+
+	x, err := SolveLeastSquares(A, b)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !mat.EqualApprox(&x, want, 1e-12) {
+		t.Errorf("x = %v, want %v", x.RawVector().Data, want.RawVector().Data)
+	}
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/alexandru-balan/go-rk-rk/sparse"
+	"gonum.org/v1/gonum/mat"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrMatrixMarket is returned when a MatrixMarket file is malformed or uses a format that is not supported
+var ErrMatrixMarket = errors.New("invalid matrix market file")
+
+// maxReserved is the largest number of entries reserved up front when reading a coordinate file
+const maxReserved = 1 << 20
+
+// ReadMatrixMarket reads a real matrix in MatrixMarket (.mtx) format, the format of the SuiteSparse collection.
+//
+// Matrices in coordinate format are returned as a *sparse.CSR, so large sparse test matrices can be passed
+// to algorithms.RandomizedKaczmarzSparse without ever being stored densely. Matrices in array format are
+// returned as a *mat.Dense.
+//
+// The real, integer and pattern fields are supported; pattern entries are read as ones. General, symmetric and
+// skew-symmetric matrices are supported and only the stored triangle has to be in the file. Comment lines
+// starting with % and blank lines are skipped. Complex and hermitian matrices are rejected with an ErrMatrixMarket.
+func ReadMatrixMarket(r io.Reader) (mat.Matrix, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	// The header line
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: empty file", ErrMatrixMarket)
+	}
+	header := strings.Fields(strings.ToLower(scanner.Text()))
+	if len(header) != 5 || header[0] != "%%matrixmarket" || header[1] != "matrix" {
+		return nil, fmt.Errorf("%w: bad header %q", ErrMatrixMarket, scanner.Text())
+	}
+	format, field, symmetry := header[2], header[3], header[4]
+
+	if format != "coordinate" && format != "array" {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrMatrixMarket, format)
+	}
+	if field != "real" && field != "integer" && field != "pattern" {
+		return nil, fmt.Errorf("%w: unsupported field %q", ErrMatrixMarket, field)
+	}
+	if field == "pattern" && format == "array" {
+		return nil, fmt.Errorf("%w: pattern matrices must be in coordinate format", ErrMatrixMarket)
+	}
+	if symmetry != "general" && symmetry != "symmetric" && symmetry != "skew-symmetric" {
+		return nil, fmt.Errorf("%w: unsupported symmetry %q", ErrMatrixMarket, symmetry)
+	}
+
+	// The remaining lines hold the size and then the entries, with comments and blank lines in between
+	next := func() ([]string, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "%") {
+				continue
+			}
+			return strings.Fields(line), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: unexpected end of file", ErrMatrixMarket)
+	}
+
+	size, err := next()
+	if err != nil {
+		return nil, err
+	}
+	dims, err := parseInts(size)
+	if err != nil {
+		return nil, err
+	}
+	if (format == "coordinate" && len(dims) != 3) || (format == "array" && len(dims) != 2) {
+		return nil, fmt.Errorf("%w: bad size line %q", ErrMatrixMarket, strings.Join(size, " "))
+	}
+	rows, cols := dims[0], dims[1]
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("%w: the matrix is %dx%d", ErrMatrixMarket, rows, cols)
+	}
+	if symmetry != "general" && rows != cols {
+		return nil, fmt.Errorf("%w: a %s matrix must be square, got %dx%d", ErrMatrixMarket, symmetry, rows, cols)
+	}
+
+	if format == "array" {
+		return readArray(next, rows, cols, symmetry)
+	}
+	return readCoordinate(next, rows, cols, dims[2], field, symmetry)
+}
+
+// readCoordinate reads the nnz entries of a coordinate MatrixMarket file into a CSR matrix
+func readCoordinate(next func() ([]string, error), rows, cols, nnz int, field, symmetry string) (*sparse.CSR, error) {
+	// nnz can't exceed rows*cols, which is compared by division since the product may overflow
+	if nnz < 0 || (nnz > 0 && (nnz-1)/cols >= rows) {
+		return nil, fmt.Errorf("%w: %d entries in a %dx%d matrix", ErrMatrixMarket, nnz, rows, cols)
+	}
+
+	// The header is trusted for the entries it announces only up to a point, a truncated file must not reserve
+	// gigabytes before failing
+	capacity := nnz
+	if capacity > maxReserved {
+		capacity = maxReserved
+	}
+	is := make([]int, 0, capacity)
+	js := make([]int, 0, capacity)
+	values := make([]float64, 0, capacity)
+
+	for k := 0; k < nnz; k++ {
+		entry, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if (field == "pattern" && len(entry) != 2) || (field != "pattern" && len(entry) != 3) {
+			return nil, fmt.Errorf("%w: bad entry %q", ErrMatrixMarket, strings.Join(entry, " "))
+		}
+
+		index, err := parseInts(entry[:2])
+		if err != nil {
+			return nil, err
+		}
+		i, j := index[0]-1, index[1]-1
+		if i < 0 || i >= rows || j < 0 || j >= cols {
+			return nil, fmt.Errorf("%w: entry (%d, %d) is outside the %dx%d matrix", ErrMatrixMarket, i+1, j+1, rows, cols)
+		}
+
+		value := 1.0
+		if field != "pattern" {
+			value, err = strconv.ParseFloat(entry[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMatrixMarket, err)
+			}
+		}
+
+		is, js, values = append(is, i), append(js, j), append(values, value)
+		if i != j && symmetry == "symmetric" {
+			is, js, values = append(is, j), append(js, i), append(values, value)
+		}
+		if i != j && symmetry == "skew-symmetric" {
+			is, js, values = append(is, j), append(js, i), append(values, -value)
+		}
+	}
+
+	// Counting the entries of every row and placing them after the entries of the previous rows
+	indptr := make([]int, rows+1)
+	for _, i := range is {
+		indptr[i+1]++
+	}
+	for i := 0; i < rows; i++ {
+		indptr[i+1] += indptr[i]
+	}
+
+	ind := make([]int, len(values))
+	data := make([]float64, len(values))
+	filled := make([]int, rows)
+	for k, i := range is {
+		position := indptr[i] + filled[i]
+		ind[position] = js[k]
+		data[position] = values[k]
+		filled[i]++
+	}
+
+	return sparse.NewCSR(rows, cols, indptr, ind, data), nil
+}
+
+// readArray reads the column-major entries of an array MatrixMarket file into a dense matrix
+func readArray(next func() ([]string, error), rows, cols int, symmetry string) (*mat.Dense, error) {
+	matrix := mat.NewDense(rows, cols, nil)
+
+	for j := 0; j < cols; j++ {
+		// Symmetric matrices only store the lower triangle and skew-symmetric ones the strictly lower triangle
+		start := 0
+		if symmetry == "symmetric" {
+			start = j
+		} else if symmetry == "skew-symmetric" {
+			start = j + 1
+		}
+
+		for i := start; i < rows; i++ {
+			entry, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if len(entry) != 1 {
+				return nil, fmt.Errorf("%w: bad entry %q", ErrMatrixMarket, strings.Join(entry, " "))
+			}
+
+			value, err := strconv.ParseFloat(entry[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMatrixMarket, err)
+			}
+
+			matrix.Set(i, j, value)
+			if symmetry == "symmetric" {
+				matrix.Set(j, i, value)
+			} else if symmetry == "skew-symmetric" {
+				matrix.Set(j, i, -value)
+			}
+		}
+	}
+
+	return matrix, nil
+}
+
+// parseInts parses every field as an int
+func parseInts(fields []string) ([]int, error) {
+	ints := make([]int, len(fields))
+	for k, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMatrixMarket, err)
+		}
+		ints[k] = value
+	}
+
+	return ints, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"strings"
+	"testing"
+)
+
+func TestReadMatrixMarket(t *testing.T) {
+	files := []struct {
+		name string
+		file string
+		want []float64
+	}{
+		{"coordinate", `%%MatrixMarket matrix coordinate real general
+% a comment
+2 3 3
+1 1 1.5
+2 3 -2
+
+1 2 4
+`, []float64{1.5, 4, 0, 0, 0, -2}},
+		{"symmetric", `%%MatrixMarket matrix coordinate integer symmetric
+2 2 2
+1 1 1
+2 1 3
+`, []float64{1, 3, 3, 0}},
+		{"pattern", `%%MatrixMarket matrix coordinate pattern skew-symmetric
+2 2 1
+2 1
+`, []float64{0, -1, 1, 0}},
+		{"array", `%%MatrixMarket matrix array real general
+2 2
+1
+2
+3
+4
+`, []float64{1, 3, 2, 4}},
+	}
+
+	for _, file := range files {
+		matrix, err := ReadMatrixMarket(strings.NewReader(file.file))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", file.name, err)
+			continue
+		}
+		rows, cols := matrix.Dims()
+		if want := mat.NewDense(rows, cols, file.want); !mat.Equal(matrix, want) {
+			t.Errorf("%s: read %v, want %v", file.name, mat.Formatted(matrix), mat.Formatted(want))
+		}
+	}
+}
+
+func TestReadMatrixMarketMalformed(t *testing.T) {
+	files := []struct {
+		name string
+		file string
+	}{
+		{"empty", ""},
+		{"bad banner", "%%MatrixMarket tensor coordinate real general\n1 1 0\n"},
+		{"short banner", "%%MatrixMarket matrix coordinate real\n1 1 0\n"},
+		{"complex", "%%MatrixMarket matrix coordinate complex general\n1 1 0\n"},
+		{"hermitian", "%%MatrixMarket matrix coordinate real hermitian\n1 1 0\n"},
+		{"pattern array", "%%MatrixMarket matrix array pattern general\n1 1\n"},
+		{"missing size", "%%MatrixMarket matrix coordinate real general\n% only comments\n"},
+		{"short size", "%%MatrixMarket matrix coordinate real general\n2 2\n"},
+		{"bad size", "%%MatrixMarket matrix coordinate real general\n2 x 1\n"},
+		{"zero rows", "%%MatrixMarket matrix coordinate real general\n0 2 0\n"},
+		{"negative nnz", "%%MatrixMarket matrix coordinate real general\n2 2 -1\n"},
+		{"too many nnz", "%%MatrixMarket matrix coordinate real general\n2 2 5\n"},
+		{"overflowing nnz", "%%MatrixMarket matrix coordinate real general\n4294967296 4294967296 9223372036854775807\n"},
+		{"non-square symmetric", "%%MatrixMarket matrix coordinate real symmetric\n2 3 0\n"},
+		{"truncated", "%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n"},
+		{"out of range", "%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n"},
+		{"bad value", "%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 x\n"},
+	}
+
+	for _, file := range files {
+		if _, err := ReadMatrixMarket(strings.NewReader(file.file)); !errors.Is(err, ErrMatrixMarket) {
+			t.Errorf("%s: got error %v, want %v", file.name, err, ErrMatrixMarket)
+		}
+	}
+}
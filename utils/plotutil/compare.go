@@ -0,0 +1,106 @@
+package plotutil
+
+import (
+	"fmt"
+	"github.com/alexandru-balan/go-rk-rk/algorithms"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot/plotter"
+	"path/filepath"
+)
+
+// SolverSpec is a solver variant run by Compare
+type SolverSpec struct {
+	// Name labels the curve of the variant in the legend
+	Name string
+	// Solve runs the variant on A*x=b. It must keep the errors, e.g. by passing keepErrors to the solver, and
+	// pass opts on to the solver, which holds the seed of the run when Compare is given WithMasterSeed.
+	Solve func(A mat.Matrix, b *mat.VecDense, opts ...algorithms.Option) (mat.VecDense, []float64, error)
+	// Style is the look of the curve of the variant. Its zero fields are taken from DefaultStyle for the index of
+	// the variant.
+	Style PlotStyle
+}
+
+// CompareOption is an optional setting of Compare
+type CompareOption func(*compareOptions)
+
+// compareOptions holds the settings of Compare
+type compareOptions struct {
+	master *uint64
+}
+
+// WithMasterSeed makes the whole comparison reproducible from a single seed: variant k is run with
+// algorithms.WithSubstream(seed, k), so every variant draws its rows independently of the others but the same
+// master seed always reproduces the same curves.
+func WithMasterSeed(seed uint64) CompareOption {
+	return func(o *compareOptions) {
+		o.master = &seed
+	}
+}
+
+// Compare runs every variant on the system A*x=b and draws their errors as lines of different colors on a single
+// logarithmic plot with a legend, saved to path.
+//
+// The variants usually wrap the solvers of the algorithms package, e.g.
+//
+//	{Name: "RK", Solve: func(A mat.Matrix, b *mat.VecDense, opts ...algorithms.Option) (mat.VecDense, []float64, error) {
+//		return algorithms.RandomizedKaczmarz(A, b, 10_000, 1e-20, true, opts...)
+//	}}
+//
+// The variants run one after the other on the same A and b, which they must not modify. The format, the size and
+// the parent directories of path are handled as in PlotConvergence, but an empty path is an error.
+// Returns an error naming the first variant that fails or keeps no errors, or if the plot can't be built or saved.
+func Compare(A mat.Matrix, b *mat.VecDense, variants []SolverSpec, path string, opts ...CompareOption) error {
+	o := compareOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := checkFormat(filepath.Ext(path)); err != nil {
+		return err
+	}
+	p, err := newPlot(Options{})
+	if err != nil {
+		return err
+	}
+
+	// STEP 1.
+	// Running every variant and collecting its errors
+	series := make([]plotter.XYs, len(variants))
+	for k, variant := range variants {
+		var solveOpts []algorithms.Option
+		if o.master != nil {
+			solveOpts = append(solveOpts, algorithms.WithSubstream(*o.master, k))
+		}
+
+		_, errors, err := variant.Solve(A, b, solveOpts...)
+		if err != nil {
+			return fmt.Errorf("running %s: %w", variant.Name, err)
+		}
+		if len(errors) == 0 {
+			return fmt.Errorf("running %s: no errors were kept", variant.Name)
+		}
+
+		series[k] = make(plotter.XYs, len(errors))
+		for i := range series[k] {
+			series[k][i].X = float64(i)
+			series[k][i].Y = errors[i]
+		}
+	}
+
+	// STEP 2.
+	// Drawing the errors of every variant as a line on a shared logarithmic axis
+	logScale(p, series...)
+	for k, points := range series {
+		style := variants[k].Style.or(DefaultStyle(k))
+
+		thumbnails, err := addLine(p, points, style)
+		if err != nil {
+			return fmt.Errorf("creating line of %s: %w", variants[k].Name, err)
+		}
+
+		p.Legend.Add(variants[k].Name, thumbnails...)
+	}
+	p.Legend.Top = true
+
+	return save(p, path, Options{})
+}
@@ -0,0 +1,60 @@
+package plotutil
+
+import (
+	"bytes"
+	"github.com/alexandru-balan/go-rk-rk/algorithms"
+	"gonum.org/v1/gonum/mat"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// compareRuns runs Compare with two variants of the same solver and returns the errors of every variant and the
+// bytes of the saved plot
+func compareRuns(t *testing.T, path string, opts ...CompareOption) ([][]float64, []byte) {
+	t.Helper()
+	A := mat.NewDense(4, 2, []float64{1, 0, 0, 1, 1, 1, 1, -1})
+	b := mat.NewVecDense(4, []float64{1, 1, 3, 1})
+
+	runs := make([][]float64, 2)
+	variant := func(k int) SolverSpec {
+		return SolverSpec{Name: "RK", Solve: func(A mat.Matrix, b *mat.VecDense, opts ...algorithms.Option) (mat.VecDense, []float64, error) {
+			x, errs, err := algorithms.RandomizedKaczmarz(A, b, 200, 0, true, opts...)
+			runs[k] = errs
+			return x, errs, err
+		}}
+	}
+
+	if err := Compare(A, b, []SolverSpec{variant(0), variant(1)}, path, opts...); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	plot, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return runs, plot
+}
+
+func TestCompareMasterSeed(t *testing.T) {
+	dir := tempDir(t)
+
+	first, firstPlot := compareRuns(t, filepath.Join(dir, "first.svg"), WithMasterSeed(42))
+	second, secondPlot := compareRuns(t, filepath.Join(dir, "second.svg"), WithMasterSeed(42))
+	if !reflect.DeepEqual(first, second) {
+		t.Error("the same master seed gave different errors")
+	}
+	if !bytes.Equal(firstPlot, secondPlot) {
+		t.Error("the same master seed gave different plots")
+	}
+
+	// Every variant has a substream of its own, and another master seed gives other substreams
+	if reflect.DeepEqual(first[0], first[1]) {
+		t.Error("the two variants drew the same rows")
+	}
+	other, _ := compareRuns(t, filepath.Join(dir, "other.svg"), WithMasterSeed(43))
+	if reflect.DeepEqual(first, other) {
+		t.Error("another master seed gave the same errors")
+	}
+}
@@ -0,0 +1,323 @@
+// Package plotutil plots the convergence of the solvers.
+//
+// It is kept apart from the utils package so that programs which only solve systems don't depend on gonum/plot.
+package plotutil
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+	"image"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned when a plot is saved to a file whose extension isn't an image format of gonum/plot
+var ErrUnsupportedFormat = errors.New("unsupported plot format")
+
+// formats holds the extensions gonum/plot can save to
+var formats = map[string]bool{
+	".eps": true, ".jpg": true, ".jpeg": true, ".pdf": true, ".png": true, ".svg": true, ".tif": true, ".tiff": true,
+}
+
+// PlotConvergence draws the errors returned by a solver as a scatter plot against their iteration and saves it
+// to path under the given title.
+//
+// The solvers never plot by themselves, so this works for the errors of any of them.
+// The format is chosen by the extension of path: .png, .svg, .pdf, .eps, .jpg or .tif. The plot is 400 points
+// wide and high, see PlotConvergenceSize for other sizes.
+// The parent directories of path are created if they are missing. If path is empty nothing is plotted.
+// Returns an ErrUnsupportedFormat for any other extension, or an error if the plot can't be built or saved.
+func PlotConvergence(errors []float64, title, path string) error {
+	return PlotConvergenceSize(errors, title, path, 400, 400)
+}
+
+// PlotConvergenceSize is PlotConvergence with a plot of the given width and height, e.g. 4*vg.Inch.
+// Vector formats like .svg and .pdf keep the size as is, raster formats draw it at 96 dots per inch.
+func PlotConvergenceSize(errors []float64, title, path string, width, height vg.Length) error {
+	return PlotConvergenceStyle(errors, title, path, width, height, Scatter)
+}
+
+// Style is the way the errors are drawn by PlotConvergenceStyle
+type Style int
+
+const (
+	// Scatter draws every error as a point on a linear axis
+	Scatter Style = iota
+	// LogLine joins the errors with a line on a logarithmic axis, where linear convergence is a straight line
+	LogLine
+)
+
+// PlotConvergenceStyle is PlotConvergenceSize drawing the errors in the given style.
+//
+// A logarithmic axis can't show errors that are zero or negative, like the last one of a solve that hit the
+// exact solution, so LogLine raises them to the smallest positive error. If there's none they are drawn at 1e-10.
+// A flat curve is drawn in the middle of an axis spanning two decades.
+func PlotConvergenceStyle(errors []float64, title, path string, width, height vg.Length, style Style) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("plot size must be positive, got %v*%v", width, height)
+	}
+
+	return PlotConvergenceOptions(errors, path, Options{Title: title, Width: width, Height: height, Style: style})
+}
+
+// Options are the settings of PlotConvergenceOptions. The zero value of every field keeps the look of
+// PlotConvergence.
+type Options struct {
+	// Title is drawn above the plot. No title is drawn if it is empty.
+	Title string
+	// XLabel and YLabel label the axes, "iterations" and "error" if they are empty
+	XLabel string
+	YLabel string
+	// HideGrid removes the grid drawn behind the errors
+	HideGrid bool
+	// Width and Height are the size of the plot, 400 points if they are zero
+	Width  vg.Length
+	Height vg.Length
+	// Style is the way the errors are drawn
+	Style Style
+	// Series is the look of the errors. Its zero fields keep the look of PlotConvergence: DefaultStyle(0) with
+	// circles for Scatter.
+	Series PlotStyle
+}
+
+// PlotConvergenceOptions is PlotConvergence with all the settings of the plot given by opts, so plots of
+// different solvers can be told apart.
+func PlotConvergenceOptions(errors []float64, path string, opts Options) error {
+	if path == "" {
+		return nil
+	}
+	if err := checkFormat(filepath.Ext(path)); err != nil {
+		return err
+	}
+
+	p, err := drawConvergence(errors, opts)
+	if err != nil {
+		return err
+	}
+
+	return save(p, path, opts)
+}
+
+// WriteConvergence draws the errors as PlotConvergenceOptions does and writes the plot to w in the given format,
+// e.g. "png" or "svg", without touching the filesystem, so a web service can send it as the body of a response.
+// Returns an ErrUnsupportedFormat for a format gonum/plot can't write, or an error if the plot can't be built
+// or written.
+func WriteConvergence(w io.Writer, errors []float64, format string, opts Options) error {
+	if err := checkFormat("." + format); err != nil {
+		return err
+	}
+
+	p, err := drawConvergence(errors, opts)
+	if err != nil {
+		return err
+	}
+
+	width, height := size(opts)
+	writer, err := p.WriterTo(width, height, strings.ToLower(format))
+	if err != nil {
+		return fmt.Errorf("rendering plot: %w", err)
+	}
+	if _, err := writer.WriteTo(w); err != nil {
+		return fmt.Errorf("writing plot: %w", err)
+	}
+
+	return nil
+}
+
+// ConvergenceImage draws the errors as PlotConvergenceOptions does and returns the plot as an image, rendered
+// at 96 dots per inch on a white background like the raster formats.
+// Returns an error if the plot can't be built.
+func ConvergenceImage(errors []float64, opts Options) (image.Image, error) {
+	p, err := drawConvergence(errors, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := vgimg.New(size(opts))
+	p.Draw(draw.New(canvas))
+
+	return canvas.Image(), nil
+}
+
+// drawConvergence returns the plot of the errors with the settings of opts
+func drawConvergence(errors []float64, opts Options) (*plot.Plot, error) {
+	p, err := newPlot(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make(plotter.XYs, len(errors))
+	for i := range points {
+		points[i].X = float64(i)
+		points[i].Y = errors[i]
+	}
+
+	switch opts.Style {
+	case LogLine:
+		logScale(p, points)
+
+		if _, err := addLine(p, points, opts.Series.or(DefaultStyle(0))); err != nil {
+			return nil, fmt.Errorf("creating line: %w", err)
+		}
+	case Scatter:
+		p.Y.Min = math.Pow(10, -10)
+
+		defaults := DefaultStyle(0)
+		defaults.Shape = draw.CircleGlyph{}
+		style := opts.Series.or(defaults)
+
+		scatter, err := plotter.NewScatter(points)
+		if err != nil {
+			return nil, fmt.Errorf("creating scatter: %w", err)
+		}
+
+		scatter.GlyphStyle = style.glyph()
+
+		p.Add(scatter)
+	default:
+		return nil, fmt.Errorf("unknown plot style %d", opts.Style)
+	}
+
+	return p, nil
+}
+
+// checkFormat returns an ErrUnsupportedFormat if gonum/plot can't save to files with the extension ext
+func checkFormat(ext string) error {
+	if ext = strings.ToLower(ext); !formats[ext] {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, ext)
+	}
+
+	return nil
+}
+
+// newPlot checks the size in opts and returns an empty plot with the title, labels and grid of opts
+func newPlot(opts Options) (*plot.Plot, error) {
+	if opts.Width < 0 || opts.Height < 0 {
+		return nil, fmt.Errorf("plot size must be positive, got %v*%v", opts.Width, opts.Height)
+	}
+
+	xLabel, yLabel := opts.XLabel, opts.YLabel
+	if xLabel == "" {
+		xLabel = "iterations"
+	}
+	if yLabel == "" {
+		yLabel = "error"
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return nil, fmt.Errorf("creating plot: %w", err)
+	}
+
+	p.Title.Text = opts.Title
+	p.X.Label.Text = xLabel
+	p.Y.Label.Text = yLabel
+	if !opts.HideGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	return p, nil
+}
+
+// logScale puts the y axis of p on a logarithmic scale and raises the errors of all the series that it can't show
+// to their smallest positive error, or to 1e-10 if there's none
+func logScale(p *plot.Plot, series ...plotter.XYs) {
+	floor := math.Inf(1)
+	for _, points := range series {
+		for _, point := range points {
+			if point.Y > 0 && point.Y < floor {
+				floor = point.Y
+			}
+		}
+	}
+	if math.IsInf(floor, 1) {
+		floor = math.Pow(10, -10)
+	}
+
+	highest := floor
+	for _, points := range series {
+		for i := range points {
+			if !(points[i].Y > 0) {
+				points[i].Y = floor
+			}
+			highest = math.Max(highest, points[i].Y)
+		}
+	}
+
+	// A flat curve would otherwise get an axis reaching below zero
+	if highest == floor {
+		p.Y.Min, p.Y.Max = floor/10, floor*10
+	}
+	p.Y.Scale = plot.LogScale{}
+	p.Y.Tick.Marker = decadeTicks{}
+}
+
+// decadeTicks marks the powers of ten of a logarithmic axis like plot.LogTicks, but labels them as 1e-k and only
+// labels every few of them when the axis spans many decades
+type decadeTicks struct{}
+
+// Ticks returns the ticks of the range between min and max
+func (decadeTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := plot.LogTicks{}.Ticks(min, max)
+
+	labelled := 0
+	for _, tick := range ticks {
+		if tick.Label != "" {
+			labelled++
+		}
+	}
+	every := (labelled + 9) / 10
+
+	for i := range ticks {
+		if ticks[i].Label == "" {
+			continue
+		}
+		exponent := int(math.Round(math.Log10(ticks[i].Value)))
+		if exponent%every != 0 {
+			ticks[i].Label = ""
+			continue
+		}
+		ticks[i].Value = math.Pow(10, float64(exponent))
+		ticks[i].Label = fmt.Sprintf("1e%d", exponent)
+	}
+
+	return ticks
+}
+
+// size returns the width and height of opts, 400 points for those that are zero
+func size(opts Options) (vg.Length, vg.Length) {
+	width, height := opts.Width, opts.Height
+	if width == 0 {
+		width = 400
+	}
+	if height == 0 {
+		height = 400
+	}
+
+	return width, height
+}
+
+// save saves p to path at the size of opts, creating the missing parent directories
+func save(p *plot.Plot, path string, opts Options) error {
+	width, height := size(opts)
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("creating plot directory: %w", err)
+	}
+
+	err = p.Save(width, height, path)
+	if err != nil {
+		return fmt.Errorf("saving plot: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package plotutil
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempDir returns a new temporary directory, removed at the end of the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "plotutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	return dir
+}
+
+func TestPlotConvergence(t *testing.T) {
+	path := filepath.Join(tempDir(t), "plots", "errors.png")
+
+	if err := PlotConvergence([]float64{4, 2, 1, 0.5}, "errors", path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Errorf("no plot saved to %s: %v", path, err)
+	}
+}
+
+func TestPlotConvergenceFailures(t *testing.T) {
+	dir := tempDir(t)
+
+	// A regular file where a directory is expected makes the save fail
+	blocker := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := PlotConvergence([]float64{1, 0.5}, "errors", filepath.Join(blocker, "errors.png")); err == nil {
+		t.Error("expected an error for a path that can't be written")
+	}
+
+	err := PlotConvergence([]float64{1, 0.5}, "errors", filepath.Join(dir, "errors.txt"))
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("got error %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
+
+func TestConvergenceImageDecodes(t *testing.T) {
+	errs := []float64{4, 2, 1, 0.5, 0.25}
+
+	// The file saved to disk
+	path := filepath.Join(tempDir(t), "errors.png")
+	if err := PlotConvergenceOptions(errs, path, Options{Title: "errors"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	saved, format, err := image.Decode(file)
+	if err != nil || format != "png" {
+		t.Fatalf("the saved plot doesn't decode as a png: %q, %v", format, err)
+	}
+
+	// The bytes written to memory
+	var buf bytes.Buffer
+	if err := WriteConvergence(&buf, errs, "png", Options{Title: "errors"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	written, format, err := image.Decode(&buf)
+	if err != nil || format != "png" {
+		t.Fatalf("the written plot doesn't decode as a png: %q, %v", format, err)
+	}
+
+	// The image rendered without encoding it
+	rendered, err := ConvergenceImage(errs, Options{Title: "errors"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if saved.Bounds().Empty() || written.Bounds() != saved.Bounds() || rendered.Bounds() != saved.Bounds() {
+		t.Errorf("bounds %v saved, %v written and %v rendered", saved.Bounds(), written.Bounds(), rendered.Bounds())
+	}
+}
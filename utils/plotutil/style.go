@@ -0,0 +1,90 @@
+package plotutil
+
+import (
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"image/color"
+)
+
+// PlotStyle is the look of one series of errors, see Options and SolverSpec.
+//
+// A zero field takes its value from the default style of the series, so a style only needs the fields it changes.
+type PlotStyle struct {
+	// Color is the color of the line and of the glyphs
+	Color color.Color
+	// Shape is the glyph drawn at every error, e.g. draw.CrossGlyph{}. Lines are drawn without glyphs if it is nil.
+	Shape draw.GlyphDrawer
+	// Radius is the radius of the glyphs
+	Radius vg.Length
+	// LineWidth is the width of the line joining the errors
+	LineWidth vg.Length
+}
+
+// palette holds the colors of DefaultStyle, reused in order when there are more series. The first one is the
+// color of PlotConvergence, the others are colorblind safe.
+var palette = []color.Color{
+	color.RGBA{R: 255, B: 128, A: 255},
+	color.RGBA{G: 114, B: 178, A: 255},
+	color.RGBA{G: 158, B: 115, A: 255},
+	color.RGBA{R: 230, G: 159, A: 255},
+	color.RGBA{R: 86, G: 180, B: 233, A: 255},
+	color.RGBA{R: 213, G: 94, A: 255},
+	color.RGBA{R: 204, G: 121, B: 167, A: 255},
+	color.RGBA{A: 255},
+}
+
+// DefaultStyle returns the style of the k-th series of a plot: the k-th color of a palette of 8 colors that
+// starts over for more series, glyphs of radius 2 and a line of width 1, with no glyph shape.
+func DefaultStyle(k int) PlotStyle {
+	return PlotStyle{
+		Color:     palette[k%len(palette)],
+		Radius:    vg.Points(2),
+		LineWidth: vg.Points(1),
+	}
+}
+
+// or returns s with its zero fields taken from defaults
+func (s PlotStyle) or(defaults PlotStyle) PlotStyle {
+	if s.Color == nil {
+		s.Color = defaults.Color
+	}
+	if s.Shape == nil {
+		s.Shape = defaults.Shape
+	}
+	if s.Radius == 0 {
+		s.Radius = defaults.Radius
+	}
+	if s.LineWidth == 0 {
+		s.LineWidth = defaults.LineWidth
+	}
+
+	return s
+}
+
+// glyph returns the glyph style of s
+func (s PlotStyle) glyph() draw.GlyphStyle {
+	return draw.GlyphStyle{Color: s.Color, Radius: s.Radius, Shape: s.Shape}
+}
+
+// addLine adds the points to p as a line in style s, with glyphs if s has a shape, and returns the thumbnails
+// of the legend entry of the line
+func addLine(p *plot.Plot, points plotter.XYs, s PlotStyle) ([]plot.Thumbnailer, error) {
+	line, scatter, err := plotter.NewLinePoints(points)
+	if err != nil {
+		return nil, err
+	}
+
+	line.LineStyle.Color = s.Color
+	line.LineStyle.Width = s.LineWidth
+	p.Add(line)
+	if s.Shape == nil {
+		return []plot.Thumbnailer{line}, nil
+	}
+
+	scatter.GlyphStyle = s.glyph()
+	p.Add(scatter)
+
+	return []plot.Thumbnailer{line, scatter}, nil
+}